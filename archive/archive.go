@@ -0,0 +1,198 @@
+// Package archive архивирует записи разговоров из журнала звонков в BlobStore:
+// локальный каталог или S3-совместимое хранилище.
+package archive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+)
+
+const DefaultPathTemplate = "{siteId}/{date}/{callId}.mp3"
+
+// Source - источник звонков и записей. Реализуется *calltouch.Client.
+type Source interface {
+	CallsDiary(ctx context.Context, siteID int, period calltouch.Period, options map[string]bool) ([]calltouch.Call, error)
+	DownloadRecording(ctx context.Context, siteID int, callID int, w io.Writer) (calltouch.Recording, error)
+}
+
+type Status string
+
+const (
+	StatusArchived    Status = "archived"     // Запись скачана и сохранена.
+	StatusSkipped     Status = "skipped"      // Запись уже была в архиве.
+	StatusNoRecording Status = "no_recording" // У звонка нет записи.
+	StatusFailed      Status = "failed"       // Ошибка, см. Result.Err.
+)
+
+// Result - результат архивации записи одного звонка.
+type Result struct {
+	CallID   int
+	Key      string
+	Status   Status
+	Checksum string
+	Err      error
+}
+
+// Archiver скачивает записи разговоров за период и сохраняет их в BlobStore.
+type Archiver struct {
+	source Source
+	store  BlobStore
+
+	// PathTemplate - шаблон ключа объекта. Подстановки: {siteId}, {callId}, {date} (2006-01-02),
+	// {year}, {month}, {day}. По умолчанию DefaultPathTemplate.
+	PathTemplate string
+	// Concurrency - количество одновременных загрузок. По умолчанию 4.
+	Concurrency int
+	// Verify заставляет скачивать записи, уже имеющиеся в архиве, и перезаписывать их при несовпадении
+	// контрольной суммы. Без Verify объект с сохраненной контрольной суммой считается заархивированным.
+	Verify bool
+	// TempDir - каталог для временных файлов загрузки. По умолчанию os.TempDir().
+	TempDir string
+}
+
+func NewArchiver(source Source, store BlobStore) *Archiver {
+	return &Archiver{
+		source:       source,
+		store:        store,
+		PathTemplate: DefaultPathTemplate,
+		Concurrency:  4,
+	}
+}
+
+// Archive архивирует записи всех звонков сайта за период. Результаты возвращаются в порядке журнала звонков;
+// ошибка возвращается, только если не удалось получить журнал.
+func (a *Archiver) Archive(ctx context.Context, siteID int, period calltouch.Period) ([]Result, error) {
+	calls, err := a.source.CallsDiary(ctx, siteID, period, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := a.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	results := make([]Result, len(calls))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for i, call := range calls {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = Result{CallID: call.CallID, Status: StatusFailed, Err: ctx.Err()}
+
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, call calltouch.Call) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i] = a.archiveCall(ctx, siteID, call)
+		}(i, call)
+	}
+
+	wg.Wait()
+
+	return results, nil
+}
+
+func (a *Archiver) archiveCall(ctx context.Context, siteID int, call calltouch.Call) Result {
+	result := Result{CallID: call.CallID, Key: a.key(siteID, call)}
+
+	existing, exists, err := a.store.Stat(ctx, result.Key)
+	if err != nil {
+		return failed(result, err)
+	}
+
+	if exists && existing.Checksum != "" && !a.Verify {
+		result.Status = StatusSkipped
+		result.Checksum = existing.Checksum
+
+		return result
+	}
+
+	tmp, err := os.CreateTemp(a.TempDir, "calltouch-recording-*")
+	if err != nil {
+		return failed(result, err)
+	}
+
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	hash := sha256.New()
+
+	recording, err := a.source.DownloadRecording(ctx, siteID, call.CallID, io.MultiWriter(tmp, hash))
+	if errors.Is(err, calltouch.ErrRecordingNotFound) {
+		result.Status = StatusNoRecording
+
+		return result
+	}
+	if err != nil {
+		return failed(result, err)
+	}
+
+	result.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	if exists && existing.Checksum == result.Checksum {
+		result.Status = StatusSkipped
+
+		return result
+	}
+
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return failed(result, err)
+	}
+
+	err = a.store.Put(ctx, result.Key, tmp, recording.Written, result.Checksum)
+	if err != nil {
+		return failed(result, err)
+	}
+
+	result.Status = StatusArchived
+
+	return result
+}
+
+func (a *Archiver) key(siteID int, call calltouch.Call) string {
+	template := a.PathTemplate
+	if template == "" {
+		template = DefaultPathTemplate
+	}
+
+	date, year, month, day := "unknown", "unknown", "unknown", "unknown"
+	if t, err := call.Time(); err == nil {
+		date, year, month, day = t.Format("2006-01-02"), t.Format("2006"), t.Format("01"), t.Format("02")
+	}
+
+	return strings.NewReplacer(
+		"{siteId}", strconv.Itoa(siteID),
+		"{callId}", strconv.Itoa(call.CallID),
+		"{date}", date,
+		"{year}", year,
+		"{month}", month,
+		"{day}", day,
+	).Replace(template)
+}
+
+func failed(result Result, err error) Result {
+	result.Status = StatusFailed
+	result.Err = err
+
+	return result
+}
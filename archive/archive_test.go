@@ -0,0 +1,102 @@
+package archive_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+	"github.com/mg-realcom/calltouch-sdk/archive"
+)
+
+type fakeSource struct {
+	recording string
+	downloads int32
+}
+
+func (s *fakeSource) CallsDiary(_ context.Context, _ int, _ calltouch.Period, _ map[string]bool) ([]calltouch.Call, error) {
+	return []calltouch.Call{{CallID: 7, Date: "02/01/2024 10:00:00"}}, nil
+}
+
+func (s *fakeSource) DownloadRecording(_ context.Context, _ int, _ int, w io.Writer) (calltouch.Recording, error) {
+	atomic.AddInt32(&s.downloads, 1)
+
+	n, err := io.WriteString(w, s.recording)
+
+	return calltouch.Recording{Written: int64(n)}, err
+}
+
+func TestArchiverChecksumSkipping(t *testing.T) {
+	t.Parallel()
+
+	sum := sha256.Sum256([]byte("recording"))
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name          string
+		stored        string // Содержимое в архиве, пусто - объекта нет.
+		checksum      string // Сохраненная контрольная сумма.
+		verify        bool
+		wantStatus    archive.Status
+		wantDownloads int32
+		wantContent   string
+	}{
+		{name: "new object", wantStatus: archive.StatusArchived, wantDownloads: 1, wantContent: "recording"},
+		{name: "stored checksum skips download", stored: "recording", checksum: checksum, wantStatus: archive.StatusSkipped, wantContent: "recording"},
+		{name: "stored checksum trusted without verify", stored: "corrupt", checksum: checksum, wantStatus: archive.StatusSkipped, wantContent: "corrupt"},
+		{name: "missing checksum is re-archived", stored: "partial", wantStatus: archive.StatusArchived, wantDownloads: 1, wantContent: "recording"},
+		{name: "verify with matching checksum", stored: "recording", checksum: checksum, verify: true, wantStatus: archive.StatusSkipped, wantDownloads: 1, wantContent: "recording"},
+		{name: "verify with mismatching checksum", stored: "corrupt", checksum: "bad", verify: true, wantStatus: archive.StatusArchived, wantDownloads: 1, wantContent: "recording"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			store := archive.NewLocalStore(t.TempDir())
+			key := "1/2024-01-02/7.mp3"
+
+			if tt.stored != "" {
+				err := store.Put(ctx, key, strings.NewReader(tt.stored), int64(len(tt.stored)), tt.checksum)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			source := &fakeSource{recording: "recording"}
+
+			archiver := archive.NewArchiver(source, store)
+			archiver.Verify = tt.verify
+			archiver.TempDir = t.TempDir()
+
+			results, err := archiver.Archive(ctx, 1, calltouch.Period{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(results) != 1 || results[0].Status != tt.wantStatus || results[0].Key != key {
+				t.Fatalf("Archive() = %+v, want status %s for key %s", results, tt.wantStatus, key)
+			}
+
+			if n := atomic.LoadInt32(&source.downloads); n != tt.wantDownloads {
+				t.Errorf("downloads = %d, want %d", n, tt.wantDownloads)
+			}
+
+			info, ok, err := store.Stat(ctx, key)
+			if err != nil || !ok {
+				t.Fatalf("Stat() = %v, %v", ok, err)
+			}
+
+			if info.Size != int64(len(tt.wantContent)) {
+				t.Errorf("stored size = %d, want %d", info.Size, len(tt.wantContent))
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BlobInfo описывает сохраненный объект.
+type BlobInfo struct {
+	Size     int64
+	Checksum string // SHA-256 содержимого в hex, записанный при сохранении.
+}
+
+// BlobStore - хранилище архивных записей.
+type BlobStore interface {
+	// Stat возвращает сведения об объекте; ok == false, если объекта нет.
+	Stat(ctx context.Context, key string) (info BlobInfo, ok bool, err error)
+	// Put сохраняет объект размером size с контрольной суммой checksum (SHA-256 в hex).
+	Put(ctx context.Context, key string, r io.ReadSeeker, size int64, checksum string) error
+}
+
+// LocalStore хранит записи в каталоге локальной файловой системы.
+// Контрольная сумма хранится рядом с файлом в файле с суффиксом .sha256.
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) *LocalStore {
+	return &LocalStore{
+		root: root,
+	}
+}
+
+func (s *LocalStore) Stat(_ context.Context, key string) (BlobInfo, bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return BlobInfo{}, false, err
+	}
+
+	stat, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return BlobInfo{}, false, nil
+	}
+	if err != nil {
+		return BlobInfo{}, false, err
+	}
+
+	checksum, err := os.ReadFile(path + ".sha256")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return BlobInfo{}, false, err
+	}
+
+	return BlobInfo{Size: stat.Size(), Checksum: strings.TrimSpace(string(checksum))}, true, nil
+}
+
+func (s *LocalStore) Put(_ context.Context, key string, r io.ReadSeeker, _ int64, checksum string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	// Контрольная сумма пишется последней: ее наличие означает, что файл сохранен полностью.
+	return os.WriteFile(path+".sha256", []byte(checksum+"\n"), 0o644)
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash("/" + key))
+	if clean == string(filepath.Separator) {
+		return "", errors.New("archive: empty key")
+	}
+
+	return filepath.Join(s.root, clean), nil
+}
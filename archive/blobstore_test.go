@@ -0,0 +1,98 @@
+package archive_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mg-realcom/calltouch-sdk/archive"
+)
+
+func TestLocalStoreKeySanitisation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key  string
+		want string // Путь относительно корня хранилища.
+	}{
+		{key: "1/2024-01-02/5.mp3", want: "1/2024-01-02/5.mp3"},
+		{key: "/1/5.mp3", want: "1/5.mp3"},
+		{key: "../5.mp3", want: "5.mp3"},
+		{key: "../../etc/passwd", want: "etc/passwd"},
+		{key: "1/../../5.mp3", want: "5.mp3"},
+		{key: "1/./2//5.mp3", want: "1/2/5.mp3"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.key, func(t *testing.T) {
+			t.Parallel()
+
+			root := filepath.Join(t.TempDir(), "root")
+			store := archive.NewLocalStore(root)
+
+			err := store.Put(context.Background(), tt.key, strings.NewReader("data"), 4, "abc")
+			if err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(tt.want)))
+			if err != nil {
+				t.Fatalf("object not stored at %s: %v", tt.want, err)
+			}
+
+			if string(data) != "data" {
+				t.Errorf("stored %q, want %q", data, "data")
+			}
+		})
+	}
+}
+
+func TestLocalStoreEmptyKey(t *testing.T) {
+	t.Parallel()
+
+	store := archive.NewLocalStore(t.TempDir())
+
+	for _, key := range []string{"", "/", "..", "../.."} {
+		if err := store.Put(context.Background(), key, strings.NewReader("data"), 4, "abc"); err == nil {
+			t.Errorf("Put(%q) succeeded, want error", key)
+		}
+	}
+}
+
+func TestLocalStoreStat(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root := t.TempDir()
+	store := archive.NewLocalStore(root)
+
+	_, ok, err := store.Stat(ctx, "a/1.mp3")
+	if err != nil || ok {
+		t.Fatalf("Stat() of missing object = %v, %v; want not found", ok, err)
+	}
+
+	err = store.Put(ctx, "a/1.mp3", strings.NewReader("data"), 4, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, ok, err := store.Stat(ctx, "a/1.mp3")
+	if err != nil || !ok || info.Size != 4 || info.Checksum != "abc" {
+		t.Fatalf("Stat() = %+v, %v, %v; want size 4, checksum abc", info, ok, err)
+	}
+
+	// Файл без контрольной суммы - незавершенная запись: объект есть, но сумма пустая.
+	err = os.Remove(filepath.Join(root, "a", "1.mp3.sha256"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, ok, err = store.Stat(ctx, "a/1.mp3")
+	if err != nil || !ok || info.Checksum != "" {
+		t.Errorf("Stat() without checksum = %+v, %v, %v; want found with empty checksum", info, ok, err)
+	}
+}
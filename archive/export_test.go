@@ -0,0 +1,11 @@
+package archive
+
+import (
+	"net/http"
+	"time"
+)
+
+// SignRequest открывает подпись запроса с заданным временем для проверки по эталонным примерам AWS.
+func SignRequest(s *S3Store, req *http.Request, payloadHash string, headers map[string]string, now time.Time) {
+	s.sign(req, payloadHash, headers, now)
+}
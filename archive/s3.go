@@ -0,0 +1,197 @@
+package archive
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Store хранит записи в S3-совместимом хранилище (AWS S3, MinIO, Yandex Object Storage и т.п.).
+// Используется адресация path-style: Endpoint/Bucket/key. Контрольная сумма хранится в метаданных x-amz-meta-sha256.
+type S3Store struct {
+	Endpoint  string // Адрес хранилища, например https://storage.yandexcloud.net или http://localhost:9000.
+	Region    string // Регион подписи, например ru-central1 или us-east-1.
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Client    *http.Client // По умолчанию http.DefaultClient.
+}
+
+func (s *S3Store) Stat(ctx context.Context, key string) (BlobInfo, bool, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil, emptyPayloadHash, nil)
+	if err != nil {
+		return BlobInfo{}, false, err
+	}
+
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return BlobInfo{}, false, err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return BlobInfo{Size: resp.ContentLength, Checksum: resp.Header.Get("X-Amz-Meta-Sha256")}, true, nil
+	case http.StatusNotFound:
+		return BlobInfo{}, false, nil
+	default:
+		return BlobInfo{}, false, fmt.Errorf("s3: head %s: status code - %v", key, resp.StatusCode)
+	}
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, r io.ReadSeeker, size int64, checksum string) error {
+	headers := map[string]string{
+		"x-amz-meta-sha256": checksum,
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, io.NopCloser(r), checksum, headers)
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+	req.GetBody = func() (io.ReadCloser, error) {
+		_, sErr := r.Seek(0, io.SeekStart)
+
+		return io.NopCloser(r), sErr
+	}
+
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("s3: put %s: status code - %v, body - %s", key, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+func (s *S3Store) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+
+	return http.DefaultClient
+}
+
+// newRequest создает запрос к объекту key, подписанный AWS Signature Version 4.
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body io.ReadCloser, payloadHash string, headers map[string]string) (*http.Request, error) {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.Path = "/" + s.Bucket + "/" + strings.TrimPrefix(key, "/")
+	endpoint.RawPath = s3EscapePath(endpoint.Path)
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sign(req, payloadHash, headers, time.Now())
+
+	return req, nil
+}
+
+// sign подписывает запрос AWS Signature Version 4 на момент now. Кроме headers подписываются
+// host, x-amz-content-sha256 и x-amz-date; строка запроса в подпись не входит.
+func (s *S3Store) sign(req *http.Request, payloadHash string, headers map[string]string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	signed := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	for k, v := range headers {
+		signed[strings.ToLower(k)] = v
+	}
+
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(signed[name]) + "\n")
+
+		if name != "host" {
+			req.Header.Set(name, signed[name])
+		}
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, s.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+// s3EscapePath кодирует путь по правилам SigV4: все, кроме незарезервированных символов и '/'.
+func s3EscapePath(path string) string {
+	var b strings.Builder
+
+	for _, c := range []byte(path) {
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+
+			continue
+		}
+
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,150 @@
+// Package autotag присваивает звонкам теги по декларативным правилам,
+// проверяющим фразы разговора, длительность, источник и номера в тексте.
+package autotag
+
+import (
+	"context"
+	"strings"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+)
+
+// Tagger добавляет теги звонку. Реализуется *calltouch.Client.
+type Tagger interface {
+	AddCallTags(ctx context.Context, siteID int, callID int, names ...string) error
+}
+
+// Decision - результат применения правила к звонку.
+type Decision struct {
+	CallID  int
+	Rule    string
+	Tags    []string // Теги, которых у звонка еще нет.
+	Applied bool     // Теги отправлены в Calltouch.
+	Err     error
+}
+
+// AutoTagger применяет правила к звонкам. В режиме DryRun теги только вычисляются.
+type AutoTagger struct {
+	tagger Tagger
+	rules  []Rule
+	DryRun bool
+}
+
+func New(tagger Tagger, rules []Rule) *AutoTagger {
+	return &AutoTagger{
+		tagger: tagger,
+		rules:  rules,
+	}
+}
+
+// Evaluate возвращает решения по звонку без обращения к API. Теги, уже присвоенные звонку
+// (CallTags) или добавленные предыдущим правилом, повторно не добавляются.
+func (a *AutoTagger) Evaluate(call calltouch.Call) []Decision {
+	existing := make(map[string]bool)
+	for _, name := range call.TagNames() {
+		existing[strings.ToLower(name)] = true
+	}
+
+	decisions := make([]Decision, 0)
+
+	for _, rule := range a.rules {
+		if !matches(rule, call) {
+			continue
+		}
+
+		tags := make([]string, 0, len(rule.Tags))
+		for _, tag := range rule.Tags {
+			if !existing[strings.ToLower(tag)] {
+				existing[strings.ToLower(tag)] = true
+				tags = append(tags, tag)
+			}
+		}
+
+		if len(tags) > 0 {
+			decisions = append(decisions, Decision{CallID: call.CallID, Rule: rule.Name, Tags: tags})
+		}
+	}
+
+	return decisions
+}
+
+// Apply вычисляет решения для звонков и, если не включен DryRun, добавляет теги через Tagger.
+// Ошибки добавления записываются в Decision.Err.
+func (a *AutoTagger) Apply(ctx context.Context, siteID int, calls []calltouch.Call) []Decision {
+	decisions := make([]Decision, 0)
+
+	for _, call := range calls {
+		for _, decision := range a.Evaluate(call) {
+			if !a.DryRun {
+				decision.Err = a.tagger.AddCallTags(ctx, siteID, decision.CallID, decision.Tags...)
+				decision.Applied = decision.Err == nil
+			}
+
+			decisions = append(decisions, decision)
+		}
+	}
+
+	return decisions
+}
+
+func matches(rule Rule, call calltouch.Call) bool {
+	if rule.MinDuration > 0 && call.Duration < rule.MinDuration {
+		return false
+	}
+
+	if rule.MaxDuration > 0 && call.Duration > rule.MaxDuration {
+		return false
+	}
+
+	if len(rule.Sources) > 0 && !containsFold(rule.Sources, call.Source) {
+		return false
+	}
+
+	if len(rule.UtmCampaigns) > 0 && !containsFold(rule.UtmCampaigns, call.UtmCampaign) {
+		return false
+	}
+
+	if rule.PhonesInText != nil {
+		hasPhones := call.PhonesInText != nil && len(*call.PhonesInText) > 0
+		if hasPhones != *rule.PhonesInText {
+			return false
+		}
+	}
+
+	if len(rule.Keywords) > 0 && !phrasesContain(call, rule.Keywords, rule.Channel) {
+		return false
+	}
+
+	return true
+}
+
+func phrasesContain(call calltouch.Call, keywords []string, channel *int) bool {
+	if call.Phrases == nil {
+		return false
+	}
+
+	for _, phrase := range *call.Phrases {
+		if channel != nil && phrase.Channel != *channel {
+			continue
+		}
+
+		message := strings.ToLower(phrase.Message)
+		for _, keyword := range keywords {
+			if strings.Contains(message, strings.ToLower(keyword)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,148 @@
+package autotag_test
+
+import (
+	"reflect"
+	"testing"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+	"github.com/mg-realcom/calltouch-sdk/autotag"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	operator, yes, no := 1, true, false
+
+	phrases := func(p ...calltouch.Phrase) *[]calltouch.Phrase { return &p }
+	tags := func(names ...string) *[]calltouch.Tag {
+		return &[]calltouch.Tag{{Category: "manual", Type: "manual", Names: names}}
+	}
+
+	tests := []struct {
+		name string
+		rule autotag.Rule
+		call calltouch.Call
+		want []string
+	}{
+		{
+			name: "keyword ignores case",
+			rule: autotag.Rule{Keywords: []string{"Доставка"}},
+			call: calltouch.Call{Phrases: phrases(calltouch.Phrase{Channel: 0, Message: "а ДОСТАВКА есть?"})},
+			want: []string{"tag"},
+		},
+		{
+			name: "any keyword matches",
+			rule: autotag.Rule{Keywords: []string{"скидка", "акция"}},
+			call: calltouch.Call{Phrases: phrases(calltouch.Phrase{Message: "это акция?"})},
+			want: []string{"tag"},
+		},
+		{
+			name: "keyword missing",
+			rule: autotag.Rule{Keywords: []string{"скидка"}},
+			call: calltouch.Call{Phrases: phrases(calltouch.Phrase{Message: "добрый день"})},
+		},
+		{
+			name: "keyword without phrases",
+			rule: autotag.Rule{Keywords: []string{"скидка"}},
+			call: calltouch.Call{},
+		},
+		{
+			name: "keyword in other channel",
+			rule: autotag.Rule{Keywords: []string{"скидка"}, Channel: &operator},
+			call: calltouch.Call{Phrases: phrases(calltouch.Phrase{Channel: 0, Message: "скидка"})},
+		},
+		{
+			name: "keyword in selected channel",
+			rule: autotag.Rule{Keywords: []string{"скидка"}, Channel: &operator},
+			call: calltouch.Call{Phrases: phrases(calltouch.Phrase{Channel: 1, Message: "скидка"})},
+			want: []string{"tag"},
+		},
+		{name: "min duration met", rule: autotag.Rule{MinDuration: 30}, call: calltouch.Call{Duration: 30}, want: []string{"tag"}},
+		{name: "min duration not met", rule: autotag.Rule{MinDuration: 30}, call: calltouch.Call{Duration: 29}},
+		{name: "max duration met", rule: autotag.Rule{MaxDuration: 10}, call: calltouch.Call{Duration: 10}, want: []string{"tag"}},
+		{name: "max duration exceeded", rule: autotag.Rule{MaxDuration: 10}, call: calltouch.Call{Duration: 11}},
+		{name: "source matches", rule: autotag.Rule{Sources: []string{"yandex", "google"}}, call: calltouch.Call{Source: "Google"}, want: []string{"tag"}},
+		{name: "source differs", rule: autotag.Rule{Sources: []string{"yandex"}}, call: calltouch.Call{Source: "google"}},
+		{name: "campaign matches", rule: autotag.Rule{UtmCampaigns: []string{"spring"}}, call: calltouch.Call{UtmCampaign: "spring"}, want: []string{"tag"}},
+		{name: "campaign differs", rule: autotag.Rule{UtmCampaigns: []string{"spring"}}, call: calltouch.Call{UtmCampaign: "autumn"}},
+		{
+			name: "phones in text required",
+			rule: autotag.Rule{PhonesInText: &yes},
+			call: calltouch.Call{PhonesInText: &[]string{"+79991234567"}},
+			want: []string{"tag"},
+		},
+		{name: "phones in text missing", rule: autotag.Rule{PhonesInText: &yes}, call: calltouch.Call{PhonesInText: &[]string{}}},
+		{name: "phones in text absent", rule: autotag.Rule{PhonesInText: &no}, call: calltouch.Call{}, want: []string{"tag"}},
+		{
+			name: "all conditions must match",
+			rule: autotag.Rule{MinDuration: 30, Sources: []string{"yandex"}},
+			call: calltouch.Call{Duration: 60, Source: "google"},
+		},
+		{
+			name: "existing tag is not added again",
+			rule: autotag.Rule{MinDuration: 1, Tags: []string{"Target", "hot"}},
+			call: calltouch.Call{Duration: 60, CallTags: tags("target")},
+			want: []string{"hot"},
+		},
+		{
+			name: "all tags exist",
+			rule: autotag.Rule{MinDuration: 1, Tags: []string{"target"}},
+			call: calltouch.Call{Duration: 60, CallTags: tags("TARGET")},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rule := tt.rule
+			rule.Name = "rule"
+			if rule.Tags == nil {
+				rule.Tags = []string{"tag"}
+			}
+
+			got := autotag.New(nil, []autotag.Rule{rule}).Evaluate(tt.call)
+
+			want := []autotag.Decision{}
+			if tt.want != nil {
+				want = []autotag.Decision{{CallID: tt.call.CallID, Rule: "rule", Tags: tt.want}}
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Evaluate() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestEvaluateSkipsTagsOfPreviousRules(t *testing.T) {
+	t.Parallel()
+
+	rules := []autotag.Rule{
+		{Name: "long", Tags: []string{"target"}, MinDuration: 60},
+		{Name: "google", Tags: []string{"Target", "google"}, Sources: []string{"google"}},
+		{Name: "again", Tags: []string{"google"}, Sources: []string{"google"}},
+	}
+	call := calltouch.Call{CallID: 5, Duration: 90, Source: "google"}
+
+	a := autotag.New(nil, rules)
+
+	want := []autotag.Decision{
+		{CallID: 5, Rule: "long", Tags: []string{"target"}},
+		{CallID: 5, Rule: "google", Tags: []string{"google"}},
+	}
+
+	got := a.Evaluate(call)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Evaluate() = %+v, want %+v", got, want)
+	}
+
+	// Повторное вычисление по звонку с уже присвоенными тегами ничего не добавляет.
+	call.CallTags = &[]calltouch.Tag{{Names: []string{"target", "google"}}}
+
+	if got := a.Evaluate(call); len(got) != 0 {
+		t.Errorf("Evaluate() on tagged call = %+v, want none", got)
+	}
+}
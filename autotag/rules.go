@@ -0,0 +1,94 @@
+package autotag
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule - декларативное правило: если звонок удовлетворяет всем заданным условиям, ему добавляются Tags.
+// Внутри списка условия объединяются по ИЛИ, незаданные условия не проверяются.
+type Rule struct {
+	Name         string   `json:"name" yaml:"name"`
+	Tags         []string `json:"tags" yaml:"tags"`
+	Keywords     []string `json:"keywords,omitempty" yaml:"keywords,omitempty"`         // Подстроки во фразах разговора, без учета регистра.
+	Channel      *int     `json:"channel,omitempty" yaml:"channel,omitempty"`           // Канал фраз для Keywords: 1 - оператор, 0 - клиент.
+	MinDuration  int      `json:"minDuration,omitempty" yaml:"minDuration,omitempty"`   // Минимальная длительность разговора в секундах.
+	MaxDuration  int      `json:"maxDuration,omitempty" yaml:"maxDuration,omitempty"`   // Максимальная длительность разговора в секундах.
+	Sources      []string `json:"sources,omitempty" yaml:"sources,omitempty"`           // Допустимые значения Source.
+	UtmCampaigns []string `json:"utmCampaigns,omitempty" yaml:"utmCampaigns,omitempty"` // Допустимые значения UtmCampaign.
+	PhonesInText *bool    `json:"phonesInText,omitempty" yaml:"phonesInText,omitempty"` // Наличие (true) или отсутствие (false) номеров в тексте разговора.
+}
+
+type ruleFile struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Validate проверяет, что у правила есть имя, теги и хотя бы одно условие.
+func (r Rule) Validate() error {
+	if r.Name == "" {
+		return errors.New("autotag: rule name is required")
+	}
+
+	if len(r.Tags) == 0 {
+		return fmt.Errorf("autotag: rule %q has no tags", r.Name)
+	}
+
+	if len(r.Keywords) == 0 && r.MinDuration == 0 && r.MaxDuration == 0 && len(r.Sources) == 0 &&
+		len(r.UtmCampaigns) == 0 && r.PhonesInText == nil {
+		return fmt.Errorf("autotag: rule %q has no conditions", r.Name)
+	}
+
+	return nil
+}
+
+// ParseRules разбирает правила из YAML или JSON вида {"rules": [...]}.
+func ParseRules(data []byte, format string) ([]Rule, error) {
+	var file ruleFile
+
+	switch strings.ToLower(format) {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+
+		err := decoder.Decode(&file)
+		if err != nil {
+			return nil, err
+		}
+	case "yaml", "yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+
+		err := decoder.Decode(&file)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("autotag: unknown rules format %q", format)
+	}
+
+	for _, rule := range file.Rules {
+		err := rule.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return file.Rules, nil
+}
+
+// LoadRules читает правила из файла. Формат определяется по расширению: .json, .yaml или .yml.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseRules(data, strings.TrimPrefix(filepath.Ext(path), "."))
+}
@@ -0,0 +1,86 @@
+package autotag_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mg-realcom/calltouch-sdk/autotag"
+)
+
+func TestParseRules(t *testing.T) {
+	t.Parallel()
+
+	channel, phones := 1, true
+
+	want := []autotag.Rule{
+		{Name: "discount", Tags: []string{"discount"}, Keywords: []string{"скидка"}, Channel: &channel},
+		{Name: "long", Tags: []string{"target", "long"}, MinDuration: 60, MaxDuration: 600, Sources: []string{"yandex"}, UtmCampaigns: []string{"spring"}},
+		{Name: "phones", Tags: []string{"phone"}, PhonesInText: &phones},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		data    string
+		want    []autotag.Rule
+		wantErr bool
+	}{
+		{
+			name:   "yaml",
+			format: "yaml",
+			data: `
+rules:
+  - name: discount
+    tags: [discount]
+    keywords: [скидка]
+    channel: 1
+  - name: long
+    tags: [target, long]
+    minDuration: 60
+    maxDuration: 600
+    sources: [yandex]
+    utmCampaigns: [spring]
+  - name: phones
+    tags: [phone]
+    phonesInText: true
+`,
+			want: want,
+		},
+		{
+			name:   "json",
+			format: "JSON",
+			data: `{"rules": [
+				{"name": "discount", "tags": ["discount"], "keywords": ["скидка"], "channel": 1},
+				{"name": "long", "tags": ["target", "long"], "minDuration": 60, "maxDuration": 600, "sources": ["yandex"], "utmCampaigns": ["spring"]},
+				{"name": "phones", "tags": ["phone"], "phonesInText": true}
+			]}`,
+			want: want,
+		},
+		{name: "yml extension", format: "yml", data: "rules: []", want: []autotag.Rule{}},
+		{name: "unknown yaml field", format: "yaml", data: "rules:\n  - name: a\n    tags: [a]\n    minDuraton: 5\n", wantErr: true},
+		{name: "unknown json field", format: "json", data: `{"rules": [{"name": "a", "tags": ["a"], "minDuraton": 5}]}`, wantErr: true},
+		{name: "unknown top-level field", format: "json", data: `{"rule": []}`, wantErr: true},
+		{name: "missing name", format: "json", data: `{"rules": [{"tags": ["a"], "minDuration": 5}]}`, wantErr: true},
+		{name: "missing tags", format: "json", data: `{"rules": [{"name": "a", "minDuration": 5}]}`, wantErr: true},
+		{name: "missing conditions", format: "json", data: `{"rules": [{"name": "a", "tags": ["a"]}]}`, wantErr: true},
+		{name: "invalid json", format: "json", data: `{"rules": [`, wantErr: true},
+		{name: "unknown format", format: "toml", data: `rules = []`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := autotag.ParseRules([]byte(tt.data), tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package calltouch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	CallphaseConnected    = "callconnected"    // Звонок идет в данный момент.
+	CallphaseDisconnected = "calldisconnected" // Звонок завершен, данные еще обрабатываются.
+	CallphaseCompleted    = "callcompleted"    // Звонок завершен и полностью обработан.
+)
+
+// IsFinal сообщает, что звонок полностью обработан и его данные больше не будут дополняться автоматически.
+func (c Call) IsFinal() bool {
+	return c.Callphase == CallphaseCompleted
+}
+
+// Call возвращает один звонок по его идентификатору.
+func (c *Client) Call(ctx context.Context, siteID int, callID int, options map[string]bool) (Call, error) {
+	u := c.singleCallURLBuilder(siteID, callID, options)
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if reqErr != nil {
+		return Call{}, reqErr
+	}
+
+	resp, respErr := http.DefaultClient.Do(req)
+	if respErr != nil {
+		return Call{}, respErr
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Call{}, newAPIError(resp)
+	}
+
+	var call Call
+
+	err := json.NewDecoder(resp.Body).Decode(&call)
+	if err != nil {
+		return Call{}, err
+	}
+
+	c.applyPhoneNormalizer(&call)
+
+	return call, nil
+}
+
+func (c *Client) singleCallURLBuilder(siteID int, callID int, options map[string]bool) url.URL {
+	u := url.URL{
+		Scheme: "https",
+		Host:   "api.calltouch.ru",
+		Path:   fmt.Sprintf("calls-service/RestAPI/%v/calls-diary/calls/%v", siteID, callID),
+	}
+
+	params := url.Values{}
+	params.Add("clientApiId", c.accessToken)
+
+	for k, v := range options {
+		params.Add(k, strconv.FormatBool(v))
+	}
+	u.RawQuery = params.Encode()
+
+	return u
+}
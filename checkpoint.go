@@ -0,0 +1,151 @@
+package calltouch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	EndpointCalls = "calls"
+	EndpointLeads = "leads"
+)
+
+// Checkpoint - последняя полностью синхронизированная точка: дата и наибольший CallID/RequestID.
+type Checkpoint struct {
+	Date   time.Time `json:"date"`
+	LastID int       `json:"lastId"`
+}
+
+// CheckpointStore хранит чекпоинты синхронизации для каждой пары сайт - эндпоинт.
+type CheckpointStore interface {
+	Load(ctx context.Context, siteID int, endpoint string) (cp Checkpoint, ok bool, err error)
+	Save(ctx context.Context, siteID int, endpoint string, cp Checkpoint) error
+}
+
+func checkpointKey(siteID int, endpoint string) string {
+	return strconv.Itoa(siteID) + "/" + endpoint
+}
+
+// MemoryCheckpointStore хранит чекпоинты в памяти процесса.
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{
+		checkpoints: make(map[string]Checkpoint),
+	}
+}
+
+func (s *MemoryCheckpointStore) Load(_ context.Context, siteID int, endpoint string) (Checkpoint, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp, ok := s.checkpoints[checkpointKey(siteID, endpoint)]
+
+	return cp, ok, nil
+}
+
+func (s *MemoryCheckpointStore) Save(_ context.Context, siteID int, endpoint string, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[checkpointKey(siteID, endpoint)] = cp
+
+	return nil
+}
+
+// FileCheckpointStore хранит чекпоинты в JSON-файле. Запись атомарна: файл пишется во временный и переименовывается.
+type FileCheckpointStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{
+		path: path,
+	}
+}
+
+func (s *FileCheckpointStore) Load(_ context.Context, siteID int, endpoint string) (Checkpoint, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoints, err := s.read()
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+
+	cp, ok := checkpoints[checkpointKey(siteID, endpoint)]
+
+	return cp, ok, nil
+}
+
+func (s *FileCheckpointStore) Save(_ context.Context, siteID int, endpoint string, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoints, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	checkpoints[checkpointKey(siteID, endpoint)] = cp
+
+	return writeFileAtomic(s.path, checkpoints)
+}
+
+func (s *FileCheckpointStore) read() (map[string]Checkpoint, error) {
+	checkpoints := make(map[string]Checkpoint)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &checkpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkpoints, nil
+}
+
+func writeFileAtomic(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
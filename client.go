@@ -22,7 +22,8 @@ const (
 )
 
 type Client struct {
-	accessToken string
+	accessToken    string
+	normalizePhone func(string) string
 }
 
 func NewClient(accessToken string) *Client {
@@ -76,7 +77,10 @@ func (c *Client) CallsDiary(ctx context.Context, siteID int, period Period, opti
 		}
 
 		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("status code: %v", resp.StatusCode)
+			apiErr := newAPIError(resp)
+			resp.Body.Close()
+
+			return nil, apiErr
 		}
 
 		responseBody, err := io.ReadAll(resp.Body)
@@ -87,10 +91,14 @@ func (c *Client) CallsDiary(ctx context.Context, siteID int, period Period, opti
 		var data CallReport
 
 		jErr := json.Unmarshal(responseBody, &data)
-		if err != nil {
+		if jErr != nil {
 			return nil, jErr
 		}
 
+		for i := range data.Records {
+			c.applyPhoneNormalizer(&data.Records[i])
+		}
+
 		calls = append(calls, data.Records...)
 		rawCalls = append(rawCalls, string(responseBody))
 		isOk = data.PageTotal == data.Page
@@ -216,7 +224,7 @@ func (c *Client) LeadsDiary(ctx context.Context, period Period, options map[stri
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status code - %v, reason - %v", resp.StatusCode, resp.Status)
+		return nil, newAPIError(resp)
 	}
 
 	responseBody, err := io.ReadAll(resp.Body)
@@ -231,6 +239,12 @@ func (c *Client) LeadsDiary(ctx context.Context, period Period, options map[stri
 		return nil, err
 	}
 
+	if c.normalizePhone != nil {
+		for i := range leads {
+			leads[i].NormalizePhones(c.normalizePhone)
+		}
+	}
+
 	return leads, nil
 }
 
@@ -259,3 +273,48 @@ func (c *Client) leadURLBuilder(period Period, options map[string]bool) (url.URL
 	u.RawQuery = params.Encode()
 	return u, nil
 }
+
+// doJSON выполняет запрос с JSON-телом к lead-service API. Токен и сайт передаются в заголовках
+// Access-Token и SiteId. Ответ декодируется в v, если он не nil.
+func (c *Client) doJSON(ctx context.Context, method string, u url.URL, siteID int, body, v any) error {
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, reqErr := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if reqErr != nil {
+		return reqErr
+	}
+
+	req.Header.Set("Access-Token", c.accessToken)
+	req.Header.Set("SiteId", strconv.Itoa(siteID))
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, respErr := http.DefaultClient.Do(req)
+	if respErr != nil {
+		return respErr
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(resp)
+	}
+
+	if v == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,97 @@
+package calltouch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type commentBody struct {
+	Comment string `json:"comment"`
+}
+
+// CallComments возвращает комментарии к звонку.
+func (c *Client) CallComments(ctx context.Context, siteID int, callID int) ([]Comment, error) {
+	return c.listComments(ctx, siteID, c.callCommentsURL(siteID, callID, 0))
+}
+
+// AddCallComment добавляет комментарий к звонку и возвращает его с CommentID и PartyName.
+func (c *Client) AddCallComment(ctx context.Context, siteID int, callID int, text string) (Comment, error) {
+	return c.writeComment(ctx, http.MethodPost, siteID, c.callCommentsURL(siteID, callID, 0), text)
+}
+
+// EditCallComment заменяет текст комментария к звонку.
+func (c *Client) EditCallComment(ctx context.Context, siteID int, callID int, commentID int, text string) (Comment, error) {
+	return c.writeComment(ctx, http.MethodPut, siteID, c.callCommentsURL(siteID, callID, commentID), text)
+}
+
+// DeleteCallComment удаляет комментарий к звонку.
+func (c *Client) DeleteCallComment(ctx context.Context, siteID int, callID int, commentID int) error {
+	return c.doJSON(ctx, http.MethodDelete, c.callCommentsURL(siteID, callID, commentID), siteID, nil, nil)
+}
+
+// LeadComments возвращает комментарии к заявке.
+func (c *Client) LeadComments(ctx context.Context, siteID int, requestID int) ([]Comment, error) {
+	return c.listComments(ctx, siteID, leadCommentsURL(requestID, 0))
+}
+
+// AddLeadComment добавляет комментарий к заявке и возвращает его с CommentID и PartyName.
+func (c *Client) AddLeadComment(ctx context.Context, siteID int, requestID int, text string) (Comment, error) {
+	return c.writeComment(ctx, http.MethodPost, siteID, leadCommentsURL(requestID, 0), text)
+}
+
+// EditLeadComment заменяет текст комментария к заявке.
+func (c *Client) EditLeadComment(ctx context.Context, siteID int, requestID int, commentID int, text string) (Comment, error) {
+	return c.writeComment(ctx, http.MethodPut, siteID, leadCommentsURL(requestID, commentID), text)
+}
+
+// DeleteLeadComment удаляет комментарий к заявке.
+func (c *Client) DeleteLeadComment(ctx context.Context, siteID int, requestID int, commentID int) error {
+	return c.doJSON(ctx, http.MethodDelete, leadCommentsURL(requestID, commentID), siteID, nil, nil)
+}
+
+func (c *Client) listComments(ctx context.Context, siteID int, u url.URL) ([]Comment, error) {
+	var comments []Comment
+
+	err := c.doJSON(ctx, http.MethodGet, u, siteID, nil, &comments)
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+func (c *Client) writeComment(ctx context.Context, method string, siteID int, u url.URL, text string) (Comment, error) {
+	if strings.TrimSpace(text) == "" {
+		return Comment{}, &ValidationError{Field: "comment", Reason: "must not be empty"}
+	}
+
+	var comment Comment
+
+	err := c.doJSON(ctx, method, u, siteID, commentBody{Comment: text}, &comment)
+	if err != nil {
+		return Comment{}, asValidationError(err)
+	}
+
+	return comment, nil
+}
+
+func (c *Client) callCommentsURL(siteID int, callID int, commentID int) url.URL {
+	method := fmt.Sprintf("%v/calls/%v/comments", siteID, callID)
+	if commentID != 0 {
+		method += fmt.Sprintf("/%v", commentID)
+	}
+
+	return c.callsServiceURL(method)
+}
+
+func leadCommentsURL(requestID int, commentID int) url.URL {
+	method := fmt.Sprintf("request/%v/comments", requestID)
+	if commentID != 0 {
+		method += fmt.Sprintf("/%v", commentID)
+	}
+
+	return leadServiceURL(method)
+}
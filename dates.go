@@ -0,0 +1,21 @@
+package calltouch
+
+import "time"
+
+const CallDateTimeFormat = "02/01/2006 15:04:05"
+
+// Time возвращает дату звонка, разобранную из поля Date.
+func (c Call) Time() (time.Time, error) {
+	return time.ParseInLocation(CallDateTimeFormat, c.Date, time.Local)
+}
+
+// Time возвращает дату создания заявки из поля Date (Unix Timestamp в миллисекундах).
+func (l Lead) Time() time.Time {
+	return time.UnixMilli(l.Date)
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,224 @@
+package calltouch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldChange описывает изменение одного поля: путь в JSON-представлении записи, старое и новое значения.
+// Для добавленных элементов Old равен nil, для удаленных - New.
+type FieldChange struct {
+	Path string `json:"path"` // Путь вида callTags[category=x,type=y].names[1] или orders[orderId=1].sum.
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+// DiffCalls возвращает список различий между двумя версиями звонка.
+func DiffCalls(a, b Call) []FieldChange {
+	return diffValues(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+// DiffLeads возвращает список различий между двумя версиями заявки.
+func DiffLeads(a, b Lead) []FieldChange {
+	return diffValues(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+func diffValues(a, b reflect.Value) []FieldChange {
+	changes := make([]FieldChange, 0)
+	walkDiff("", a, b, &changes)
+
+	return changes
+}
+
+func walkDiff(path string, a, b reflect.Value, changes *[]FieldChange) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			*changes = append(*changes, FieldChange{Path: path, Old: valueOrNil(a), New: valueOrNil(b)})
+		}
+
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name := jsonFieldName(field)
+			if name == "-" {
+				continue
+			}
+
+			walkDiff(joinPath(path, name), a.Field(i), b.Field(i), changes)
+		}
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*changes = append(*changes, FieldChange{Path: path, Old: valueOrNil(a), New: valueOrNil(b)})
+			}
+
+			return
+		}
+
+		if a.Kind() == reflect.Interface && a.Elem().Type() != b.Elem().Type() {
+			*changes = append(*changes, FieldChange{Path: path, Old: a.Interface(), New: b.Interface()})
+
+			return
+		}
+
+		walkDiff(path, a.Elem(), b.Elem(), changes)
+	case reflect.Slice, reflect.Array:
+		if walkKeyedDiff(path, a, b, changes) {
+			return
+		}
+
+		n := a.Len()
+		if b.Len() > n {
+			n = b.Len()
+		}
+
+		for i := 0; i < n; i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+
+			switch {
+			case i >= a.Len():
+				*changes = append(*changes, FieldChange{Path: elemPath, New: b.Index(i).Interface()})
+			case i >= b.Len():
+				*changes = append(*changes, FieldChange{Path: elemPath, Old: a.Index(i).Interface()})
+			default:
+				walkDiff(elemPath, a.Index(i), b.Index(i), changes)
+			}
+		}
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, k := range append(a.MapKeys(), b.MapKeys()...) {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			k := keys[name]
+			walkDiff(joinPath(path, name), a.MapIndex(k), b.MapIndex(k), changes)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changes = append(*changes, FieldChange{Path: path, Old: a.Interface(), New: b.Interface()})
+		}
+	}
+}
+
+// walkKeyedDiff сравнивает списки сделок и тегов, сопоставляя элементы по ключу, а не по индексу,
+// чтобы перестановка элементов не считалась изменением. Возвращает false, если элементы не имеют ключа
+// или ключи в одной из версий повторяются: тогда списки сравниваются по индексу.
+func walkKeyedDiff(path string, a, b reflect.Value, changes *[]FieldChange) bool {
+	aKeys, ok := sliceKeys(a)
+	if !ok {
+		return false
+	}
+
+	bKeys, ok := sliceKeys(b)
+	if !ok {
+		return false
+	}
+
+	bIndex := make(map[string]int, len(bKeys))
+	for i, key := range bKeys {
+		bIndex[key] = i
+	}
+
+	for i, key := range aKeys {
+		elemPath := path + "[" + key + "]"
+
+		j, found := bIndex[key]
+		if !found {
+			*changes = append(*changes, FieldChange{Path: elemPath, Old: a.Index(i).Interface()})
+
+			continue
+		}
+
+		walkDiff(elemPath, a.Index(i), b.Index(j), changes)
+		delete(bIndex, key)
+	}
+
+	for j, key := range bKeys {
+		if _, added := bIndex[key]; added {
+			*changes = append(*changes, FieldChange{Path: path + "[" + key + "]", New: b.Index(j).Interface()})
+		}
+	}
+
+	return true
+}
+
+// sliceKeys возвращает ключи элементов списка: orderId для сделок, категорию и тип для тегов.
+func sliceKeys(v reflect.Value) ([]string, bool) {
+	keys := make([]string, v.Len())
+	unique := make(map[string]bool, v.Len())
+
+	for i := range keys {
+		var key string
+
+		switch elem := v.Index(i).Interface().(type) {
+		case CallOrder:
+			key = "orderId=" + strconv.Itoa(elem.OrderID)
+		case LeadOrder:
+			key = "orderId=" + strconv.Itoa(elem.OrderID)
+		case Tag:
+			key = "category=" + elem.Category + ",type=" + elem.Type
+		case RequestTag:
+			key = "category=" + elem.Category + ",type=" + elem.Type
+		default:
+			return nil, false
+		}
+
+		if unique[key] {
+			return nil, false
+		}
+
+		unique[key] = true
+		keys[i] = key
+	}
+
+	return keys, true
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// valueOrNil возвращает значение поля для FieldChange. Указатели разыменовываются, как в JSON-представлении записи.
+func valueOrNil(v reflect.Value) any {
+	if !v.IsValid() || ((v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil()) {
+		return nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		return v.Elem().Interface()
+	}
+
+	return v.Interface()
+}
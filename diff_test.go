@@ -0,0 +1,121 @@
+package calltouch_test
+
+import (
+	"reflect"
+	"testing"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+)
+
+func TestDiffCalls(t *testing.T) {
+	t.Parallel()
+
+	tags := func(tags ...calltouch.Tag) *[]calltouch.Tag { return &tags }
+
+	tests := []struct {
+		name string
+		a, b calltouch.Call
+		want []calltouch.FieldChange
+	}{
+		{
+			name: "equal",
+			a:    calltouch.Call{CallID: 1, Duration: 10},
+			b:    calltouch.Call{CallID: 1, Duration: 10},
+			want: []calltouch.FieldChange{},
+		},
+		{
+			name: "scalar field",
+			a:    calltouch.Call{Duration: 10},
+			b:    calltouch.Call{Duration: 20},
+			want: []calltouch.FieldChange{{Path: "duration", Old: 10, New: 20}},
+		},
+		{
+			name: "nil and empty pointer slice",
+			a:    calltouch.Call{},
+			b:    calltouch.Call{CallTags: &[]calltouch.Tag{}},
+			want: []calltouch.FieldChange{{Path: "callTags", Old: nil, New: []calltouch.Tag{}}},
+		},
+		{
+			name: "nil and empty slice",
+			a:    calltouch.Call{},
+			b:    calltouch.Call{Orders: []calltouch.CallOrder{}},
+			want: []calltouch.FieldChange{},
+		},
+		{
+			name: "attrs map in interface",
+			a:    calltouch.Call{Attrs: map[string]any{"a": "1", "b": "2"}},
+			b:    calltouch.Call{Attrs: map[string]any{"a": "1", "b": "3", "c": "4"}},
+			want: []calltouch.FieldChange{
+				{Path: "attrs.b", Old: "2", New: "3"},
+				{Path: "attrs.c", Old: nil, New: "4"},
+			},
+		},
+		{
+			name: "attrs type change",
+			a:    calltouch.Call{Attrs: "x"},
+			b:    calltouch.Call{Attrs: map[string]any{"a": "1"}},
+			want: []calltouch.FieldChange{{Path: "attrs", Old: "x", New: map[string]any{"a": "1"}}},
+		},
+		{
+			name: "reordered orders",
+			a:    calltouch.Call{Orders: []calltouch.CallOrder{{OrderID: 1}, {OrderID: 2}}},
+			b:    calltouch.Call{Orders: []calltouch.CallOrder{{OrderID: 2}, {OrderID: 1}}},
+			want: []calltouch.FieldChange{},
+		},
+		{
+			name: "order changed, added and removed",
+			a:    calltouch.Call{Orders: []calltouch.CallOrder{{OrderID: 1, PlannedAmount: 100}, {OrderID: 2}}},
+			b:    calltouch.Call{Orders: []calltouch.CallOrder{{OrderID: 3}, {OrderID: 1, PlannedAmount: 200}}},
+			want: []calltouch.FieldChange{
+				{Path: "orders[orderId=1].plannedAmount", Old: 100, New: 200},
+				{Path: "orders[orderId=2]", Old: calltouch.CallOrder{OrderID: 2}},
+				{Path: "orders[orderId=3]", New: calltouch.CallOrder{OrderID: 3}},
+			},
+		},
+		{
+			name: "reordered tags",
+			a: calltouch.Call{CallTags: tags(
+				calltouch.Tag{Category: "c", Type: "a", Names: []string{"x"}},
+				calltouch.Tag{Category: "c", Type: "b", Names: []string{"y"}},
+			)},
+			b: calltouch.Call{CallTags: tags(
+				calltouch.Tag{Category: "c", Type: "b", Names: []string{"y", "z"}},
+				calltouch.Tag{Category: "c", Type: "a", Names: []string{"x"}},
+			)},
+			want: []calltouch.FieldChange{{Path: "callTags[category=c,type=b].names[1]", New: "z"}},
+		},
+		{
+			name: "duplicate keys fall back to index",
+			a:    calltouch.Call{Orders: []calltouch.CallOrder{{OrderID: 1}, {OrderID: 1, PlannedAmount: 5}}},
+			b:    calltouch.Call{Orders: []calltouch.CallOrder{{OrderID: 1}, {OrderID: 1, PlannedAmount: 6}}},
+			want: []calltouch.FieldChange{{Path: "orders[1].plannedAmount", Old: 5, New: 6}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := calltouch.DiffCalls(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffCalls() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLeadsReorderedOrders(t *testing.T) {
+	t.Parallel()
+
+	a := calltouch.Lead{Orders: []calltouch.LeadOrder{{OrderID: 1, Sum: "10"}, {OrderID: 2, Sum: "20"}}}
+	b := calltouch.Lead{Orders: []calltouch.LeadOrder{{OrderID: 2, Sum: "20"}, {OrderID: 1, Sum: "15"}}}
+
+	got := calltouch.DiffLeads(a, b)
+	want := []calltouch.FieldChange{{Path: "orders[orderId=1].sum", Old: "10", New: "15"}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffLeads() = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package calltouch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrUnauthorized = errors.New("calltouch: token is invalid or revoked")
+	ErrNotFound     = errors.New("calltouch: not found")
+)
+
+// APIError описывает ответ API Calltouch с кодом, отличным от 2xx.
+type APIError struct {
+	StatusCode int    // HTTP-код ответа.
+	Status     string // Строка статуса ответа.
+	Body       string // Тело ответа (обрезается до 1 КБ).
+}
+
+func (e *APIError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("status code - %v, reason - %v", e.StatusCode, e.Status)
+	}
+
+	return fmt.Sprintf("status code - %v, reason - %v, body - %v", e.StatusCode, e.Status, e.Body)
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrUnauthorized) и errors.Is(err, ErrNotFound).
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return nil
+	}
+}
+
+func newAPIError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
+
+// ValidationError сообщает о некорректных данных запроса: обнаруженных до отправки или отклоненных API (400).
+type ValidationError struct {
+	Field  string // Поле запроса, если известно.
+	Reason string // Описание ошибки.
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("validation error: %v", e.Reason)
+	}
+
+	return fmt.Sprintf("validation error: %v - %v", e.Field, e.Reason)
+}
+
+// asValidationError превращает ответ 400 в ValidationError, остальные ошибки возвращает как есть.
+func asValidationError(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		return &ValidationError{Reason: apiErr.Body}
+	}
+
+	return err
+}
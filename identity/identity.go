@@ -0,0 +1,301 @@
+// Package identity объединяет звонки и заявки одного клиента в контакты по общим идентификаторам:
+// идентификаторам Calltouch, Google Analytics и Яндекс.Метрики, телефонам и адресам почты.
+package identity
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+	"github.com/mg-realcom/calltouch-sdk/phone"
+)
+
+type Kind string
+
+const (
+	KindCtCallerID Kind = "ctCallerId" // Call.CtCallerID.
+	KindCtClientID Kind = "ctClientId" // Call.CtClientID, Lead.CtClientID.
+	KindCtGlobalID Kind = "ctGlobalId" // Call.CtGlobalID, Lead.CtGlobalID, Lead.Session.CtGlobalID.
+	KindGAClientID Kind = "gaClientId" // Call.ClientID, Lead.Session.GuaClientID.
+	KindYaClientID Kind = "yaClientId" // Call.YaClientID, Lead.Session.YaClientID.
+	KindPhone      Kind = "phone"      // Call.CallerNumber, Lead.Client.Phones, в формате E.164.
+	KindEmail      Kind = "email"      // Адреса почты из Lead.Client.Contacts, в нижнем регистре.
+)
+
+// Identifier - один идентификатор клиента.
+type Identifier struct {
+	Kind  Kind   `json:"kind"`
+	Value string `json:"value"`
+}
+
+type EventType string
+
+const (
+	EventCall EventType = "call"
+	EventLead EventType = "lead"
+)
+
+// Event - звонок или заявка в хронологии контакта.
+type Event struct {
+	Type      EventType `json:"type"`
+	Time      time.Time `json:"time"` // Нулевое, если дата записи не распознана.
+	CallID    int       `json:"callId,omitempty"`
+	RequestID int       `json:"requestId,omitempty"`
+}
+
+// Contact - клиент, объединяющий все звонки и заявки с общими идентификаторами.
+type Contact struct {
+	ID          string           `json:"id"` // Стабильный идентификатор: наименьший идентификатор контакта.
+	Identifiers []Identifier     `json:"identifiers"`
+	Calls       []calltouch.Call `json:"-"`
+	Leads       []calltouch.Lead `json:"-"`
+	Timeline    []Event          `json:"timeline"`  // События по возрастанию времени, события без даты - в конце в исходном порядке.
+	FirstSeen   time.Time        `json:"firstSeen"` // Время первого события с датой, нулевое, если таких нет.
+	LastSeen    time.Time        `json:"lastSeen"`  // Время последнего события с датой, нулевое, если таких нет.
+}
+
+// Resolve объединяет звонки и заявки в контакты: две записи попадают в один контакт, если у них
+// есть общий идентификатор, в том числе транзитивно через другие записи. Записи без идентификаторов
+// образуют отдельные контакты. Результат отсортирован по FirstSeen, контакты без дат - в конце.
+func Resolve(calls []calltouch.Call, leads []calltouch.Lead) []Contact {
+	n := len(calls) + len(leads)
+	uf := newUnionFind(n)
+	ids := make([][]Identifier, n)
+	owner := make(map[Identifier]int)
+
+	for i, call := range calls {
+		ids[i] = callIdentifiers(call)
+	}
+	for i, lead := range leads {
+		ids[len(calls)+i] = leadIdentifiers(lead)
+	}
+
+	for i, list := range ids {
+		for _, id := range list {
+			if j, ok := owner[id]; ok {
+				uf.union(i, j)
+
+				continue
+			}
+
+			owner[id] = i
+		}
+	}
+
+	groups := make(map[int]*Contact)
+	seen := make(map[int]map[Identifier]bool)
+
+	for i := 0; i < n; i++ {
+		root := uf.find(i)
+
+		contact, ok := groups[root]
+		if !ok {
+			contact = &Contact{}
+			groups[root] = contact
+			seen[root] = make(map[Identifier]bool)
+		}
+
+		for _, id := range ids[i] {
+			if !seen[root][id] {
+				seen[root][id] = true
+				contact.Identifiers = append(contact.Identifiers, id)
+			}
+		}
+
+		if i < len(calls) {
+			call := calls[i]
+			contact.Calls = append(contact.Calls, call)
+
+			t, _ := call.Time()
+			contact.Timeline = append(contact.Timeline, Event{Type: EventCall, Time: t, CallID: call.CallID})
+		} else {
+			lead := leads[i-len(calls)]
+			contact.Leads = append(contact.Leads, lead)
+
+			var t time.Time
+			if lead.Date != 0 {
+				t = lead.Time()
+			}
+			contact.Timeline = append(contact.Timeline, Event{Type: EventLead, Time: t, RequestID: lead.RequestID})
+		}
+	}
+
+	contacts := make([]Contact, 0, len(groups))
+	for _, contact := range groups {
+		sort.SliceStable(contact.Timeline, func(i, j int) bool { return before(contact.Timeline[i].Time, contact.Timeline[j].Time) })
+		sort.Slice(contact.Identifiers, func(i, j int) bool {
+			a, b := contact.Identifiers[i], contact.Identifiers[j]
+			if a.Kind != b.Kind {
+				return a.Kind < b.Kind
+			}
+
+			return a.Value < b.Value
+		})
+
+		for _, event := range contact.Timeline {
+			if event.Time.IsZero() {
+				break
+			}
+
+			if contact.FirstSeen.IsZero() {
+				contact.FirstSeen = event.Time
+			}
+			contact.LastSeen = event.Time
+		}
+		contact.ID = contactID(*contact)
+
+		contacts = append(contacts, *contact)
+	}
+
+	sort.Slice(contacts, func(i, j int) bool {
+		if !contacts[i].FirstSeen.Equal(contacts[j].FirstSeen) {
+			return before(contacts[i].FirstSeen, contacts[j].FirstSeen)
+		}
+
+		return contacts[i].ID < contacts[j].ID
+	})
+
+	return contacts
+}
+
+// before упорядочивает время событий: нулевое время (дата не распознана) идет после любой даты.
+func before(a, b time.Time) bool {
+	if a.IsZero() || b.IsZero() {
+		return !a.IsZero() && b.IsZero()
+	}
+
+	return a.Before(b)
+}
+
+func contactID(c Contact) string {
+	if len(c.Identifiers) > 0 {
+		return string(c.Identifiers[0].Kind) + ":" + c.Identifiers[0].Value
+	}
+
+	event := c.Timeline[0]
+	if event.Type == EventCall {
+		return "call:" + strconv.Itoa(event.CallID)
+	}
+
+	return "lead:" + strconv.Itoa(event.RequestID)
+}
+
+func callIdentifiers(call calltouch.Call) []Identifier {
+	ids := make([]Identifier, 0)
+
+	ids = appendID(ids, KindCtCallerID, call.CtCallerID)
+	ids = appendIntID(ids, KindCtClientID, intPtr64(call.CtClientID))
+	ids = appendIntID(ids, KindCtGlobalID, intPtr64(call.CtGlobalID))
+	ids = appendID(ids, KindGAClientID, stringValue(call.ClientID))
+	ids = appendID(ids, KindYaClientID, stringValue(call.YaClientID))
+	ids = appendPhone(ids, call.CallerNumber)
+
+	return ids
+}
+
+func leadIdentifiers(lead calltouch.Lead) []Identifier {
+	ids := make([]Identifier, 0)
+
+	ids = appendIntID(ids, KindCtClientID, lead.CtClientID)
+	ids = appendIntID(ids, KindCtGlobalID, intPtr64(lead.CtGlobalID))
+	ids = appendIntID(ids, KindCtGlobalID, intPtr64(lead.Session.CtGlobalID))
+	ids = appendID(ids, KindGAClientID, lead.Session.GuaClientID)
+	ids = appendID(ids, KindYaClientID, lead.Session.YaClientID)
+
+	for _, p := range lead.Client.Phones {
+		ids = appendPhone(ids, p.PhoneNumber)
+	}
+
+	for _, contact := range lead.Client.Contacts {
+		if strings.Contains(contact.ContactValue, "@") {
+			ids = appendID(ids, KindEmail, strings.ToLower(contact.ContactValue))
+		}
+	}
+
+	return ids
+}
+
+func appendID(ids []Identifier, kind Kind, value string) []Identifier {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" || strings.EqualFold(value, "null") {
+		return ids
+	}
+
+	return append(ids, Identifier{Kind: kind, Value: value})
+}
+
+func appendIntID(ids []Identifier, kind Kind, value *int64) []Identifier {
+	if value == nil || *value == 0 {
+		return ids
+	}
+
+	return append(ids, Identifier{Kind: kind, Value: strconv.FormatInt(*value, 10)})
+}
+
+func appendPhone(ids []Identifier, value string) []Identifier {
+	number, err := phone.Default().Parse(value)
+	if err != nil {
+		return ids
+	}
+
+	return append(ids, Identifier{Kind: KindPhone, Value: number.E164})
+}
+
+func intPtr64(v *int) *int64 {
+	if v == nil {
+		return nil
+	}
+
+	n := int64(*v)
+
+	return &n
+}
+
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+
+	return *v
+}
+
+type unionFind struct {
+	parent []int
+	rank   []int
+}
+
+func newUnionFind(n int) *unionFind {
+	uf := &unionFind{parent: make([]int, n), rank: make([]int, n)}
+	for i := range uf.parent {
+		uf.parent[i] = i
+	}
+
+	return uf
+}
+
+func (uf *unionFind) find(i int) int {
+	for uf.parent[i] != i {
+		uf.parent[i] = uf.parent[uf.parent[i]]
+		i = uf.parent[i]
+	}
+
+	return i
+}
+
+func (uf *unionFind) union(a, b int) {
+	ra, rb := uf.find(a), uf.find(b)
+	if ra == rb {
+		return
+	}
+
+	if uf.rank[ra] < uf.rank[rb] {
+		ra, rb = rb, ra
+	}
+
+	uf.parent[rb] = ra
+	if uf.rank[ra] == uf.rank[rb] {
+		uf.rank[ra]++
+	}
+}
@@ -0,0 +1,195 @@
+package identity_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+	"github.com/mg-realcom/calltouch-sdk/identity"
+)
+
+func lead(t *testing.T, raw string) calltouch.Lead {
+	t.Helper()
+
+	var l calltouch.Lead
+
+	err := json.Unmarshal([]byte(raw), &l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return l
+}
+
+func strPtr(s string) *string { return &s }
+
+type member struct {
+	calls []int
+	leads []int
+}
+
+func members(contacts []identity.Contact) []member {
+	result := make([]member, 0, len(contacts))
+
+	for _, c := range contacts {
+		m := member{calls: []int{}, leads: []int{}}
+		for _, call := range c.Calls {
+			m.calls = append(m.calls, call.CallID)
+		}
+		for _, l := range c.Leads {
+			m.leads = append(m.leads, l.RequestID)
+		}
+
+		result = append(result, m)
+	}
+
+	return result
+}
+
+func TestResolveMerging(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		calls []calltouch.Call
+		leads []string
+		want  []member
+	}{
+		{
+			name: "phone in different formats",
+			calls: []calltouch.Call{
+				{CallID: 1, Date: "01/02/2024 10:00:00", CallerNumber: "8 (999) 123-45-67"},
+			},
+			leads: []string{`{"requestId": 10, "date": 1706778000000, "client": {"phones": [{"phoneNumber": "+79991234567"}]}}`},
+			want:  []member{{calls: []int{1}, leads: []int{10}}},
+		},
+		{
+			name: "email is case insensitive",
+			leads: []string{
+				`{"requestId": 10, "date": 1706778000000, "client": {"contacts": [{"contactValue": "User@Mail.ru"}]}}`,
+				`{"requestId": 11, "date": 1706864400000, "client": {"contacts": [{"contactValue": "user@mail.ru"}]}}`,
+			},
+			want: []member{{calls: []int{}, leads: []int{10, 11}}},
+		},
+		{
+			name: "transitive merge through a lead",
+			calls: []calltouch.Call{
+				{CallID: 1, Date: "01/02/2024 10:00:00", CallerNumber: "+79991234567"},
+				{CallID: 2, Date: "03/02/2024 10:00:00", ClientID: strPtr("GA1.2.3")},
+				{CallID: 3, Date: "02/02/2024 10:00:00", CallerNumber: "+79990000000"},
+			},
+			leads: []string{`{"requestId": 10, "date": 1706864400000, "session": {"guaClientId": "GA1.2.3"}, "client": {"phones": [{"phoneNumber": "89991234567"}]}}`},
+			want: []member{
+				{calls: []int{1, 2}, leads: []int{10}},
+				{calls: []int{3}, leads: []int{}},
+			},
+		},
+		{
+			name: "empty and placeholder identifiers do not merge",
+			calls: []calltouch.Call{
+				{CallID: 1, Date: "01/02/2024 10:00:00", CtCallerID: "0", ClientID: strPtr("null")},
+				{CallID: 2, Date: "02/02/2024 10:00:00", CtCallerID: "0", ClientID: strPtr("null")},
+			},
+			want: []member{{calls: []int{1}, leads: []int{}}, {calls: []int{2}, leads: []int{}}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			leads := make([]calltouch.Lead, 0, len(tt.leads))
+			for _, raw := range tt.leads {
+				leads = append(leads, lead(t, raw))
+			}
+
+			got := members(identity.Resolve(tt.calls, leads))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() members = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveStableID(t *testing.T) {
+	t.Parallel()
+
+	calls := []calltouch.Call{
+		{CallID: 1, Date: "01/02/2024 10:00:00", CallerNumber: "+79991234567", CtCallerID: "b-caller"},
+		{CallID: 2, Date: "02/02/2024 10:00:00", CtCallerID: "b-caller", YaClientID: strPtr("42")},
+		{CallID: 3, Date: "03/02/2024 10:00:00"},
+	}
+	reversed := []calltouch.Call{calls[2], calls[1], calls[0]}
+
+	first := identity.Resolve(calls, nil)
+	second := identity.Resolve(reversed, nil)
+
+	ids := func(contacts []identity.Contact) []string {
+		result := make([]string, 0, len(contacts))
+		for _, c := range contacts {
+			result = append(result, c.ID)
+		}
+
+		return result
+	}
+
+	want := []string{"ctCallerId:b-caller", "call:3"}
+
+	if got := ids(first); !reflect.DeepEqual(got, want) {
+		t.Errorf("IDs = %v, want %v", got, want)
+	}
+
+	if got := ids(second); !reflect.DeepEqual(got, want) {
+		t.Errorf("IDs for reordered input = %v, want %v", got, want)
+	}
+}
+
+func TestResolveUndatedEvents(t *testing.T) {
+	t.Parallel()
+
+	calls := []calltouch.Call{
+		{CallID: 1, Date: "garbage", CallerNumber: "+79991234567"},
+		{CallID: 2, Date: "02/02/2024 10:00:00", CallerNumber: "+79991234567"},
+		{CallID: 3, Date: "01/02/2024 10:00:00", CallerNumber: "+79991234567"},
+		{CallID: 4, Date: "", CallerNumber: "+79990000000"},
+		{CallID: 5, Date: "05/02/2024 10:00:00", CallerNumber: "+79995555555"},
+	}
+	leads := []calltouch.Lead{lead(t, `{"requestId": 10, "client": {"phones": [{"phoneNumber": "+79991234567"}]}}`)}
+
+	contacts := identity.Resolve(calls, leads)
+	if len(contacts) != 3 {
+		t.Fatalf("Resolve() returned %d contacts, want 3", len(contacts))
+	}
+
+	merged := contacts[0]
+
+	timeline := make([]int, 0, len(merged.Timeline))
+	for _, event := range merged.Timeline {
+		timeline = append(timeline, event.CallID+event.RequestID)
+	}
+
+	if want := []int{3, 2, 1, 10}; !reflect.DeepEqual(timeline, want) {
+		t.Errorf("timeline = %v, want %v", timeline, want)
+	}
+
+	firstSeen := time.Date(2024, 2, 1, 10, 0, 0, 0, time.Local)
+	lastSeen := time.Date(2024, 2, 2, 10, 0, 0, 0, time.Local)
+
+	if !merged.FirstSeen.Equal(firstSeen) || !merged.LastSeen.Equal(lastSeen) {
+		t.Errorf("FirstSeen, LastSeen = %v, %v; want %v, %v", merged.FirstSeen, merged.LastSeen, firstSeen, lastSeen)
+	}
+
+	if contacts[1].Calls[0].CallID != 5 {
+		t.Errorf("second contact = call %d, want call 5", contacts[1].Calls[0].CallID)
+	}
+
+	undated := contacts[2]
+	if undated.Calls[0].CallID != 4 || !undated.FirstSeen.IsZero() || !undated.LastSeen.IsZero() {
+		t.Errorf("undated contact = call %d, FirstSeen %v, LastSeen %v; want call 4 with zero times",
+			undated.Calls[0].CallID, undated.FirstSeen, undated.LastSeen)
+	}
+}
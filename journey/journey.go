@@ -0,0 +1,232 @@
+// Package journey восстанавливает путь клиента до звонка или заявки по истории посещений (MapVisits)
+// и считает частоты путей.
+package journey
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+)
+
+// Group - укрупненная группа канала.
+type Group string
+
+const (
+	GroupDirect   Group = "direct"
+	GroupPaid     Group = "paid"
+	GroupOrganic  Group = "organic"
+	GroupReferral Group = "referral"
+	GroupSocial   Group = "social"
+	GroupEmail    Group = "email"
+	GroupOther    Group = "other"
+)
+
+// Touchpoint - одно посещение на пути клиента.
+type Touchpoint struct {
+	Time      time.Time `json:"time"`
+	Channel   string    `json:"channel"` // Источник/канал, например yandex/cpc, или direct.
+	Group     Group     `json:"group"`
+	SessionID int       `json:"sessionId"`
+	URL       string    `json:"url"`
+}
+
+// Conversion - тип конверсии, завершающей путь.
+type Conversion string
+
+const (
+	ConversionCall Conversion = "call"
+	ConversionLead Conversion = "lead"
+)
+
+// Journey - путь клиента до конверсии.
+type Journey struct {
+	Conversion    Conversion    `json:"conversion"`
+	ConversionID  int           `json:"conversionId"` // CallID или RequestID.
+	ConvertedAt   time.Time     `json:"convertedAt"`
+	Touchpoints   []Touchpoint  `json:"touchpoints"` // Посещения по возрастанию SessionDate, посещения без даты - в конце в исходном порядке.
+	PathLength    int           `json:"pathLength"`
+	TimeToConvert time.Duration `json:"timeToConvert"` // От первого посещения с датой до конверсии, 0 если таких посещений нет.
+}
+
+// FromCall строит путь до звонка по Call.MapVisits (звонок выгружается с withMapVisits).
+func FromCall(call calltouch.Call) Journey {
+	convertedAt, _ := call.Time()
+
+	var visits []calltouch.MapVisits
+	if call.MapVisits != nil {
+		visits = *call.MapVisits
+	}
+
+	return build(ConversionCall, call.CallID, convertedAt, visits)
+}
+
+// FromLead строит путь до заявки по Lead.MapVisits (заявка выгружается с withMapVisits).
+func FromLead(lead calltouch.Lead) Journey {
+	var visits []calltouch.MapVisits
+	if lead.MapVisits != nil {
+		visits = *lead.MapVisits
+	}
+
+	return build(ConversionLead, lead.RequestID, lead.Time(), visits)
+}
+
+func build(conversion Conversion, id int, convertedAt time.Time, visits []calltouch.MapVisits) Journey {
+	j := Journey{
+		Conversion:   conversion,
+		ConversionID: id,
+		ConvertedAt:  convertedAt,
+		Touchpoints:  make([]Touchpoint, 0, len(visits)),
+	}
+
+	for _, visit := range visits {
+		channel, group := Classify(visit.Source, visit.Medium)
+
+		j.Touchpoints = append(j.Touchpoints, Touchpoint{
+			Time:      ParseSessionDate(visit.SessionDate),
+			Channel:   channel,
+			Group:     group,
+			SessionID: visit.SessionID,
+			URL:       visit.URL,
+		})
+	}
+
+	// Посещения с нераспознанной датой не переносятся в начало пути, а остаются в конце в исходном порядке.
+	sort.SliceStable(j.Touchpoints, func(a, b int) bool {
+		ta, tb := j.Touchpoints[a].Time, j.Touchpoints[b].Time
+		if ta.IsZero() || tb.IsZero() {
+			return !ta.IsZero() && tb.IsZero()
+		}
+
+		return ta.Before(tb)
+	})
+
+	j.PathLength = len(j.Touchpoints)
+
+	if j.PathLength > 0 {
+		first := j.Touchpoints[0].Time
+		if !first.IsZero() && !convertedAt.IsZero() && convertedAt.After(first) {
+			j.TimeToConvert = convertedAt.Sub(first)
+		}
+	}
+
+	return j
+}
+
+// Path возвращает путь в виде "yandex/cpc → direct → call". При collapse подряд идущие
+// одинаковые каналы схлопываются в один.
+func (j Journey) Path(collapse bool) string {
+	steps := make([]string, 0, len(j.Touchpoints)+1)
+
+	for _, tp := range j.Touchpoints {
+		if collapse && len(steps) > 0 && steps[len(steps)-1] == tp.Channel {
+			continue
+		}
+
+		steps = append(steps, tp.Channel)
+	}
+
+	steps = append(steps, string(j.Conversion))
+
+	return strings.Join(steps, " → ")
+}
+
+// PathCount - частота пути.
+type PathCount struct {
+	Path  string  `json:"path"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"` // Доля от всех путей, 0..1.
+}
+
+// PathFrequencies считает, сколько раз встречается каждый путь. Результат отсортирован
+// по убыванию частоты, при равенстве - по пути.
+func PathFrequencies(journeys []Journey, collapse bool) []PathCount {
+	counts := make(map[string]int)
+	for _, j := range journeys {
+		counts[j.Path(collapse)]++
+	}
+
+	result := make([]PathCount, 0, len(counts))
+	for path, count := range counts {
+		result = append(result, PathCount{Path: path, Count: count, Share: float64(count) / float64(len(journeys))})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+
+		return result[i].Path < result[j].Path
+	})
+
+	return result
+}
+
+// ParseSessionDate разбирает MapVisits.SessionDate. Нераспознанная дата возвращается как нулевое время.
+func ParseSessionDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms)
+	}
+
+	layouts := []string{
+		calltouch.CallDateTimeFormat,
+		"2006-01-02 15:04:05",
+		time.RFC3339,
+		"02.01.2006 15:04:05",
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// Classify возвращает название канала ("источник/канал" или "direct") и его группу.
+func Classify(source, medium string) (string, Group) {
+	source = strings.ToLower(strings.TrimSpace(source))
+	medium = strings.ToLower(strings.TrimSpace(medium))
+
+	if (source == "" || source == "(direct)" || source == "direct") && (medium == "" || medium == "(none)" || medium == "none") {
+		return "direct", GroupDirect
+	}
+
+	paidMediums := []string{"cpc", "ppc", "cpm", "cpa", "paid", "display", "banner", "retargeting"}
+	socialSources := []string{"vk", "vk.com", "ok", "ok.ru", "facebook", "instagram", "telegram", "t.me", "youtube", "dzen"}
+
+	channel := source + "/" + medium
+	if medium == "" {
+		channel = source
+	}
+
+	switch {
+	case contains(paidMediums, medium):
+		return channel, GroupPaid
+	case medium == "organic":
+		return channel, GroupOrganic
+	case medium == "email" || medium == "e-mail" || medium == "newsletter":
+		return channel, GroupEmail
+	case medium == "social" || medium == "smm" || contains(socialSources, source):
+		return channel, GroupSocial
+	case medium == "referral":
+		return channel, GroupReferral
+	default:
+		return channel, GroupOther
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
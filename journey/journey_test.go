@@ -0,0 +1,189 @@
+package journey_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+	"github.com/mg-realcom/calltouch-sdk/journey"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source, medium string
+		channel        string
+		group          journey.Group
+	}{
+		{source: "", medium: "", channel: "direct", group: journey.GroupDirect},
+		{source: "(direct)", medium: "(none)", channel: "direct", group: journey.GroupDirect},
+		{source: " Direct ", medium: "none", channel: "direct", group: journey.GroupDirect},
+		{source: "Yandex", medium: "CPC", channel: "yandex/cpc", group: journey.GroupPaid},
+		{source: "google", medium: "display", channel: "google/display", group: journey.GroupPaid},
+		{source: "google", medium: "organic", channel: "google/organic", group: journey.GroupOrganic},
+		{source: "sendsay", medium: "newsletter", channel: "sendsay/newsletter", group: journey.GroupEmail},
+		{source: "vk", medium: "", channel: "vk", group: journey.GroupSocial},
+		{source: "partner", medium: "smm", channel: "partner/smm", group: journey.GroupSocial},
+		{source: "vk.com", medium: "cpc", channel: "vk.com/cpc", group: journey.GroupPaid},
+		{source: "habr.com", medium: "referral", channel: "habr.com/referral", group: journey.GroupReferral},
+		{source: "", medium: "qr", channel: "/qr", group: journey.GroupOther},
+		{source: "offline", medium: "", channel: "offline", group: journey.GroupOther},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.source+"/"+tt.medium, func(t *testing.T) {
+			t.Parallel()
+
+			channel, group := journey.Classify(tt.source, tt.medium)
+			if channel != tt.channel || group != tt.group {
+				t.Errorf("Classify(%q, %q) = %q, %q, want %q, %q", tt.source, tt.medium, channel, group, tt.channel, tt.group)
+			}
+		})
+	}
+}
+
+func TestParseSessionDate(t *testing.T) {
+	t.Parallel()
+
+	want := time.Date(2024, time.March, 5, 14, 30, 15, 0, time.Local)
+
+	tests := []struct {
+		name string
+		in   string
+		want time.Time
+	}{
+		{name: "unix milliseconds", in: "1709649015000", want: time.UnixMilli(1709649015000)},
+		{name: "calltouch format", in: "05/03/2024 14:30:15", want: want},
+		{name: "iso without zone", in: "2024-03-05 14:30:15", want: want},
+		{name: "rfc3339", in: "2024-03-05T14:30:15+03:00", want: time.Date(2024, time.March, 5, 14, 30, 15, 0, time.FixedZone("", 3*60*60))},
+		{name: "dotted", in: " 05.03.2024 14:30:15 ", want: want},
+		{name: "empty", in: "", want: time.Time{}},
+		{name: "garbage", in: "yesterday", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := journey.ParseSessionDate(tt.in)
+			if !got.Equal(tt.want) || got.IsZero() != tt.want.IsZero() {
+				t.Errorf("ParseSessionDate(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromLeadOrdersUndatedVisitsLast(t *testing.T) {
+	t.Parallel()
+
+	visits := []calltouch.MapVisits{
+		{SessionID: 1, SessionDate: "", Source: "offline"},
+		{SessionID: 2, SessionDate: "2024-03-05 12:00:00", Source: "google", Medium: "organic"},
+		{SessionID: 3, SessionDate: "bad", Source: "vk"},
+		{SessionID: 4, SessionDate: "2024-03-05 10:00:00", Source: "yandex", Medium: "cpc"},
+	}
+	convertedAt := time.Date(2024, time.March, 5, 13, 0, 0, 0, time.Local)
+	lead := calltouch.Lead{RequestID: 8, Date: convertedAt.UnixMilli(), MapVisits: &visits}
+
+	j := journey.FromLead(lead)
+
+	gotIDs := make([]int, 0)
+	for _, tp := range j.Touchpoints {
+		gotIDs = append(gotIDs, tp.SessionID)
+	}
+
+	if want := []int{4, 2, 1, 3}; !reflect.DeepEqual(gotIDs, want) {
+		t.Errorf("touchpoint sessions = %v, want %v", gotIDs, want)
+	}
+
+	if j.PathLength != 4 {
+		t.Errorf("PathLength = %d, want 4", j.PathLength)
+	}
+
+	if j.TimeToConvert != 3*time.Hour {
+		t.Errorf("TimeToConvert = %v, want %v", j.TimeToConvert, 3*time.Hour)
+	}
+
+	if want := "yandex/cpc → google/organic → offline → vk → lead"; j.Path(false) != want {
+		t.Errorf("Path() = %q, want %q", j.Path(false), want)
+	}
+}
+
+func TestFromCallWithoutDatedVisits(t *testing.T) {
+	t.Parallel()
+
+	visits := []calltouch.MapVisits{{SessionID: 1, SessionDate: "unknown"}}
+	j := journey.FromCall(calltouch.Call{CallID: 2, Date: "05/03/2024 13:00:00", MapVisits: &visits})
+
+	if j.TimeToConvert != 0 {
+		t.Errorf("TimeToConvert = %v, want 0", j.TimeToConvert)
+	}
+
+	if want := "direct → call"; j.Path(false) != want {
+		t.Errorf("Path() = %q, want %q", j.Path(false), want)
+	}
+}
+
+func TestPathFrequencies(t *testing.T) {
+	t.Parallel()
+
+	journeyOf := func(conversion journey.Conversion, channels ...string) journey.Journey {
+		j := journey.Journey{Conversion: conversion}
+		for _, channel := range channels {
+			j.Touchpoints = append(j.Touchpoints, journey.Touchpoint{Channel: channel})
+		}
+
+		return j
+	}
+
+	journeys := []journey.Journey{
+		journeyOf(journey.ConversionCall, "yandex/cpc", "yandex/cpc", "direct"),
+		journeyOf(journey.ConversionCall, "yandex/cpc", "direct"),
+		journeyOf(journey.ConversionLead, "direct"),
+		journeyOf(journey.ConversionCall),
+	}
+
+	tests := []struct {
+		name     string
+		collapse bool
+		want     []journey.PathCount
+	}{
+		{
+			name: "as is",
+			want: []journey.PathCount{
+				{Path: "call", Count: 1, Share: 0.25},
+				{Path: "direct → lead", Count: 1, Share: 0.25},
+				{Path: "yandex/cpc → direct → call", Count: 1, Share: 0.25},
+				{Path: "yandex/cpc → yandex/cpc → direct → call", Count: 1, Share: 0.25},
+			},
+		},
+		{
+			name:     "collapsed",
+			collapse: true,
+			want: []journey.PathCount{
+				{Path: "yandex/cpc → direct → call", Count: 2, Share: 0.5},
+				{Path: "call", Count: 1, Share: 0.25},
+				{Path: "direct → lead", Count: 1, Share: 0.25},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := journey.PathFrequencies(journeys, tt.collapse)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PathFrequencies() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package calltouch
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultLeadLookback - период журнала заявок для проверки идемпотентности, если BatchOptions.Lookback не задан.
+const DefaultLeadLookback = 30 * 24 * time.Hour
+
+// BatchOptions настраивает пакетную регистрацию заявок.
+type BatchOptions struct {
+	Concurrency int    // Количество одновременных запросов. По умолчанию 4.
+	Lookback    Period // Период журнала заявок, в котором ищутся уже зарегистрированные номера. По умолчанию последние DefaultLeadLookback.
+}
+
+// LeadResult - результат регистрации одной заявки пакета.
+type LeadResult struct {
+	RequestNumber string // Номер заявки из запроса.
+	RequestID     int    // RequestID созданной или уже существующей заявки.
+	Existing      bool   // Заявка с таким номером уже была в Calltouch и повторно не отправлялась.
+	Err           error  // Ошибка регистрации.
+}
+
+// RegisterLeads регистрирует заявки пакетом, не более options.Concurrency одновременно.
+// RequestNumber служит ключом идемпотентности: заявки, номера которых уже есть в журнале заявок
+// за options.Lookback, не отправляются повторно, поэтому частично упавший пакет можно перезапустить целиком.
+// Журнал заявок не разделяется по сайтам и содержит заявки всех сайтов токена, поэтому RequestNumber
+// должен быть уникален в пределах всего аккаунта: номер, занятый на другом сайте, тоже считается существующим.
+// Результаты возвращаются в порядке заявок. Ошибка возвращается, только если не удалось прочитать журнал.
+func (c *Client) RegisterLeads(ctx context.Context, siteID int, leads []LeadRequest, options BatchOptions) ([]LeadResult, error) {
+	lookback := options.Lookback
+	if lookback.DateFrom.IsZero() {
+		now := time.Now()
+		lookback = Period{DateFrom: now.Add(-DefaultLeadLookback), DateTo: now}
+	}
+
+	registered, err := c.LeadsDiary(ctx, lookback, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]int)
+	for _, lead := range registered {
+		if lead.RequestNumber != "" {
+			existing[lead.RequestNumber] = lead.RequestID
+		}
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	results := make([]LeadResult, len(leads))
+	inBatch := make(map[string]bool)
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for i, lead := range leads {
+		results[i].RequestNumber = lead.RequestNumber
+
+		if lead.RequestNumber != "" {
+			if id, ok := existing[lead.RequestNumber]; ok {
+				results[i].RequestID = id
+				results[i].Existing = true
+
+				continue
+			}
+
+			if inBatch[lead.RequestNumber] {
+				results[i].Err = &ValidationError{Field: "requestNumber", Reason: "duplicate in batch"}
+
+				continue
+			}
+			inBatch[lead.RequestNumber] = true
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, lead LeadRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i].RequestID, results[i].Err = c.RegisterLead(ctx, siteID, lead)
+		}(i, lead)
+	}
+
+	wg.Wait()
+
+	return results, nil
+}
@@ -0,0 +1,110 @@
+package calltouch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// LeadRequest - заявка с сайта для регистрации в Calltouch.
+type LeadRequest struct {
+	Subject       string       // Название формы на сайте. Обязательное поле.
+	RequestNumber string       // Уникальный идентификатор заявки на сайте.
+	SessionID     int          // Идентификатор сессии Calltouch посетителя.
+	Fio           string       // Имя клиента.
+	PhoneNumber   string       // Номер телефона клиента.
+	Email         string       // Почта клиента.
+	Comment       string       // Комментарий к заявке.
+	RequestURL    string       // Адрес страницы, с которой отправлена форма.
+	CustomFields  []ValueField // Дополнительные поля формы.
+}
+
+// Validate проверяет обязательные поля заявки: название формы и телефон или почту клиента.
+func (r LeadRequest) Validate() error {
+	if strings.TrimSpace(r.Subject) == "" {
+		return &ValidationError{Field: "subject", Reason: "must not be empty"}
+	}
+
+	if r.PhoneNumber == "" && r.Email == "" {
+		return &ValidationError{Field: "phoneNumber", Reason: "phone number or email is required"}
+	}
+
+	if r.Email != "" && !strings.Contains(r.Email, "@") {
+		return &ValidationError{Field: "email", Reason: "must be a valid email address"}
+	}
+
+	return nil
+}
+
+type leadRequestComment struct {
+	Text string `json:"text"`
+}
+
+type leadRequestBody struct {
+	Subject       string              `json:"subject"`
+	RequestNumber string              `json:"requestNumber,omitempty"`
+	SessionID     int                 `json:"sessionId,omitempty"`
+	Fio           string              `json:"fio,omitempty"`
+	PhoneNumber   string              `json:"phoneNumber,omitempty"`
+	Email         string              `json:"email,omitempty"`
+	Comment       *leadRequestComment `json:"comment,omitempty"`
+	RequestURL    string              `json:"requestUrl,omitempty"`
+	CustomFields  []ValueField        `json:"customFields,omitempty"`
+}
+
+type leadCreateRequest struct {
+	Requests []leadRequestBody `json:"requests"`
+}
+
+type leadCreateResponse struct {
+	Data []struct {
+		RequestID     int    `json:"requestId"`
+		RequestNumber string `json:"requestNumber"`
+	} `json:"data"`
+}
+
+// RegisterLead регистрирует заявку с сайта и возвращает ее RequestID в Calltouch.
+// Некорректная заявка возвращает *ValidationError.
+func (c *Client) RegisterLead(ctx context.Context, siteID int, lead LeadRequest) (int, error) {
+	err := lead.Validate()
+	if err != nil {
+		return 0, err
+	}
+
+	body := leadRequestBody{
+		Subject:       lead.Subject,
+		RequestNumber: lead.RequestNumber,
+		SessionID:     lead.SessionID,
+		Fio:           lead.Fio,
+		PhoneNumber:   lead.PhoneNumber,
+		Email:         lead.Email,
+		RequestURL:    lead.RequestURL,
+		CustomFields:  lead.CustomFields,
+	}
+	if lead.Comment != "" {
+		body.Comment = &leadRequestComment{Text: lead.Comment}
+	}
+
+	var resp leadCreateResponse
+
+	err = c.doJSON(ctx, http.MethodPost, leadServiceURL("request/create"), siteID, leadCreateRequest{Requests: []leadRequestBody{body}}, &resp)
+	if err != nil {
+		return 0, asValidationError(err)
+	}
+
+	if len(resp.Data) == 0 {
+		return 0, errors.New("empty response from request/create")
+	}
+
+	return resp.Data[0].RequestID, nil
+}
+
+func leadServiceURL(method string) url.URL {
+	return url.URL{
+		Scheme: "https",
+		Host:   "api.calltouch.ru",
+		Path:   "lead-service/v1/api/" + method,
+	}
+}
@@ -0,0 +1,171 @@
+package calltouch
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const managerBatchSize = 100
+
+// ManagerAssignment - присвоение менеджера звонку или заявке. Заполняется CallID или RequestID.
+type ManagerAssignment struct {
+	CallID    int    // Идентификатор звонка в Calltouch.
+	RequestID int    // Идентификатор заявки в Calltouch.
+	Manager   string // ФИО менеджера.
+}
+
+type managerLead struct {
+	Type      string `json:"type"`
+	CallID    int    `json:"callId,omitempty"`
+	RequestID int    `json:"requestId,omitempty"`
+	Manager   string `json:"manager"`
+}
+
+type managerAssignBody struct {
+	Leads []managerLead `json:"leads"`
+}
+
+// AssignCallManager присваивает менеджера звонку. Значение попадает в поле Call.Manager.
+func (c *Client) AssignCallManager(ctx context.Context, siteID int, callID int, manager string) error {
+	return c.AssignManagers(ctx, siteID, []ManagerAssignment{{CallID: callID, Manager: manager}})[0]
+}
+
+// AssignLeadManager присваивает менеджера заявке. Значение попадает в поле Lead.Manager.
+func (c *Client) AssignLeadManager(ctx context.Context, siteID int, requestID int, manager string) error {
+	return c.AssignManagers(ctx, siteID, []ManagerAssignment{{RequestID: requestID, Manager: manager}})[0]
+}
+
+// AssignManagers присваивает менеджеров пакетами по 100 записей.
+// Возвращает ошибки в порядке assignments, nil для успешно присвоенных.
+func (c *Client) AssignManagers(ctx context.Context, siteID int, assignments []ManagerAssignment) []error {
+	errs := make([]error, len(assignments))
+
+	for start := 0; start < len(assignments); start += managerBatchSize {
+		end := start + managerBatchSize
+		if end > len(assignments) {
+			end = len(assignments)
+		}
+
+		leads := make([]managerLead, 0, end-start)
+		indexes := make([]int, 0, end-start)
+
+		for i := start; i < end; i++ {
+			lead, err := newManagerLead(assignments[i])
+			if err != nil {
+				errs[i] = err
+
+				continue
+			}
+
+			leads = append(leads, lead)
+			indexes = append(indexes, i)
+		}
+
+		if len(leads) == 0 {
+			continue
+		}
+
+		err := c.doJSON(ctx, http.MethodPost, leadServiceURL("manager/assign"), siteID, managerAssignBody{Leads: leads}, nil)
+		if err != nil {
+			err = asValidationError(err)
+			for _, i := range indexes {
+				errs[i] = err
+			}
+		}
+	}
+
+	return errs
+}
+
+func newManagerLead(a ManagerAssignment) (managerLead, error) {
+	if strings.TrimSpace(a.Manager) == "" {
+		return managerLead{}, &ValidationError{Field: "manager", Reason: "must not be empty"}
+	}
+
+	switch {
+	case a.CallID != 0 && a.RequestID != 0:
+		return managerLead{}, &ValidationError{Field: "callId", Reason: "either call id or request id must be set, not both"}
+	case a.CallID != 0:
+		return managerLead{Type: "call", CallID: a.CallID, Manager: a.Manager}, nil
+	case a.RequestID != 0:
+		return managerLead{Type: "request", RequestID: a.RequestID, Manager: a.Manager}, nil
+	default:
+		return managerLead{}, &ValidationError{Field: "callId", Reason: "call id or request id is required"}
+	}
+}
+
+// ParseManagerAssignments читает присвоения из CSV с заголовком. Обязательна колонка manager
+// и хотя бы одна из колонок callId, requestId. Разделитель - запятая или точка с запятой.
+func ParseManagerAssignments(r io.Reader) ([]ManagerAssignment, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.TrimLeadingSpace = true
+
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	managerCol, ok := columns["manager"]
+	if !ok {
+		return nil, errors.New("csv: manager column is required")
+	}
+
+	callCol, hasCall := columns["callid"]
+	requestCol, hasRequest := columns["requestid"]
+	if !hasCall && !hasRequest {
+		return nil, errors.New("csv: callId or requestId column is required")
+	}
+
+	assignments := make([]ManagerAssignment, 0)
+
+	for line := 2; ; line++ {
+		record, rErr := reader.Read()
+		if errors.Is(rErr, io.EOF) {
+			break
+		}
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		assignment := ManagerAssignment{Manager: strings.TrimSpace(record[managerCol])}
+
+		if hasCall && strings.TrimSpace(record[callCol]) != "" {
+			assignment.CallID, err = strconv.Atoi(strings.TrimSpace(record[callCol]))
+			if err != nil {
+				return nil, fmt.Errorf("csv: line %d: invalid callId: %w", line, err)
+			}
+		}
+
+		if hasRequest && strings.TrimSpace(record[requestCol]) != "" {
+			assignment.RequestID, err = strconv.Atoi(strings.TrimSpace(record[requestCol]))
+			if err != nil {
+				return nil, fmt.Errorf("csv: line %d: invalid requestId: %w", line, err)
+			}
+		}
+
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, nil
+}
@@ -0,0 +1,226 @@
+package calltouch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const OrderDateFormat = "2006-01-02 15:04:05"
+
+// OrderStatus - статус сделки. Набор статусов настраивается в Calltouch, ниже перечислены стандартные.
+type OrderStatus string
+
+const (
+	OrderStatusNew        OrderStatus = "new"
+	OrderStatusInProgress OrderStatus = "in_progress"
+	OrderStatusPaid       OrderStatus = "paid"
+	OrderStatusCompleted  OrderStatus = "completed"
+	OrderStatusCanceled   OrderStatus = "canceled"
+)
+
+// OrderLink связывает сделку со звонком, заявкой или телефоном клиента. Достаточно одного поля.
+type OrderLink struct {
+	CallID    int    // Идентификатор звонка в Calltouch.
+	RequestID int    // Идентификатор заявки в Calltouch.
+	Phone     string // Телефон клиента: сделка связывается с последним звонком или заявкой с этого номера.
+}
+
+// OrderRequest - данные сделки для создания или обновления.
+type OrderRequest struct {
+	OrderNumber string      // Уникальный идентификатор сделки в CRM. Обязательное поле.
+	OrderName   string      // Название сделки.
+	Status      OrderStatus // Статус сделки.
+	Amount      *float64    // Бюджет сделки в рублях; nil - не передавать, в том числе при обновлении.
+	Date        time.Time   // Дата сделки.
+	Comment     string      // Комментарий к сделке.
+	Link        OrderLink   // Связь сделки с лидом. Обязательна при создании.
+}
+
+// Order - сделка из журнала сделок.
+type Order struct {
+	OrderID     int         `json:"orderId"`     // Идентификатор сделки внутри Calltouch
+	OrderNumber string      `json:"orderNumber"` // Уникальный идентификатор сделки в CRM
+	OrderName   string      `json:"orderName"`   // Название сделки
+	Status      OrderStatus `json:"status"`      // Статус сделки
+	Amount      float64     `json:"revenue"`     // Бюджет сделки
+	OrderDate   string      `json:"orderDate"`   // Дата сделки
+	DateCreated int64       `json:"dateCreated"` // Дата и время создания сделки в миллисекундах
+	CallID      int         `json:"callId"`      // Связанный звонок
+	RequestID   int         `json:"requestId"`   // Связанная заявка
+}
+
+type orderMatching struct {
+	Type          string         `json:"type"`
+	CallParams    map[string]any `json:"callParams,omitempty"`
+	RequestParams map[string]any `json:"requestParams,omitempty"`
+}
+
+type orderBody struct {
+	Matching    []orderMatching `json:"matching,omitempty"`
+	OrderNumber string          `json:"orderNumber"`
+	OrderName   string          `json:"orderName,omitempty"`
+	Status      OrderStatus     `json:"status,omitempty"`
+	Revenue     *float64        `json:"revenue,omitempty"`
+	OrderDate   string          `json:"orderDate,omitempty"`
+	Comment     string          `json:"comment,omitempty"`
+}
+
+type orderRequestBody struct {
+	CRM    string      `json:"crm"`
+	Orders []orderBody `json:"orders"`
+}
+
+type orderResponse struct {
+	Data []struct {
+		OrderID     int    `json:"orderId"`
+		OrderNumber string `json:"orderNumber"`
+	} `json:"data"`
+}
+
+// CreateOrder создает сделку, связанную со звонком, заявкой или телефоном, и возвращает ее OrderID.
+func (c *Client) CreateOrder(ctx context.Context, siteID int, order OrderRequest) (int, error) {
+	if order.Link == (OrderLink{}) {
+		return 0, &ValidationError{Field: "link", Reason: "call id, request id or phone is required"}
+	}
+
+	body, err := newOrderBody(order)
+	if err != nil {
+		return 0, err
+	}
+
+	var resp orderResponse
+
+	err = c.doJSON(ctx, http.MethodPost, leadServiceURL("client-order/create"), siteID, orderRequestBody{CRM: "calltouch-sdk", Orders: []orderBody{body}}, &resp)
+	if err != nil {
+		return 0, asValidationError(err)
+	}
+
+	if len(resp.Data) == 0 {
+		return 0, errors.New("empty response from client-order/create")
+	}
+
+	return resp.Data[0].OrderID, nil
+}
+
+// UpdateOrder обновляет сделку с order.OrderNumber. Пустые поля и Amount == nil не изменяются.
+// Если сделки нет, ошибка удовлетворяет errors.Is(err, ErrNotFound).
+func (c *Client) UpdateOrder(ctx context.Context, siteID int, order OrderRequest) error {
+	body, err := newOrderBody(order)
+	if err != nil {
+		return err
+	}
+
+	err = c.doJSON(ctx, http.MethodPost, leadServiceURL("client-order/update"), siteID, orderRequestBody{CRM: "calltouch-sdk", Orders: []orderBody{body}}, nil)
+	if err != nil {
+		return asValidationError(err)
+	}
+
+	return nil
+}
+
+// SetOrderStatus меняет только статус сделки.
+func (c *Client) SetOrderStatus(ctx context.Context, siteID int, orderNumber string, status OrderStatus) error {
+	if status == "" {
+		return &ValidationError{Field: "status", Reason: "must not be empty"}
+	}
+
+	return c.UpdateOrder(ctx, siteID, OrderRequest{OrderNumber: orderNumber, Status: status})
+}
+
+// ListOrders возвращает сделки за период.
+func (c *Client) ListOrders(ctx context.Context, siteID int, period Period) ([]Order, error) {
+	u, err := c.ordersURLBuilder(siteID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+
+	resp, respErr := http.DefaultClient.Do(req)
+	if respErr != nil {
+		return nil, respErr
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var orders []Order
+
+	err = json.NewDecoder(resp.Body).Decode(&orders)
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (c *Client) ordersURLBuilder(siteID int, period Period) (url.URL, error) {
+	if period.DateFrom.After(period.DateTo) {
+		return url.URL{}, errors.New("dateFrom must be before dateTo")
+	}
+
+	u := url.URL{
+		Scheme: "https",
+		Host:   "api.calltouch.ru",
+		Path:   fmt.Sprintf("calls-service/RestAPI/%v/orders-diary/orders", siteID),
+	}
+
+	params := url.Values{}
+	params.Add("clientApiId", c.accessToken)
+	params.Add("dateFrom", period.DateFrom.Format(CallsDataFormat))
+	params.Add("dateTo", period.DateTo.Format(CallsDataFormat))
+	u.RawQuery = params.Encode()
+
+	return u, nil
+}
+
+func newOrderBody(order OrderRequest) (orderBody, error) {
+	if order.OrderNumber == "" {
+		return orderBody{}, &ValidationError{Field: "orderNumber", Reason: "must not be empty"}
+	}
+
+	if order.Amount != nil && *order.Amount < 0 {
+		return orderBody{}, &ValidationError{Field: "amount", Reason: "must not be negative"}
+	}
+
+	body := orderBody{
+		OrderNumber: order.OrderNumber,
+		OrderName:   order.OrderName,
+		Status:      order.Status,
+		Comment:     order.Comment,
+	}
+
+	if order.Amount != nil {
+		amount := *order.Amount
+		body.Revenue = &amount
+	}
+
+	if !order.Date.IsZero() {
+		body.OrderDate = order.Date.Format(OrderDateFormat)
+	}
+
+	switch {
+	case order.Link.CallID != 0:
+		body.Matching = []orderMatching{{Type: "call", CallParams: map[string]any{"callId": order.Link.CallID}}}
+	case order.Link.RequestID != 0:
+		body.Matching = []orderMatching{{Type: "request", RequestParams: map[string]any{"requestId": order.Link.RequestID}}}
+	case order.Link.Phone != "":
+		body.Matching = []orderMatching{
+			{Type: "call", CallParams: map[string]any{"phones": []string{order.Link.Phone}}},
+			{Type: "request", RequestParams: map[string]any{"phones": []string{order.Link.Phone}}},
+		}
+	}
+
+	return body, nil
+}
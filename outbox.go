@@ -0,0 +1,196 @@
+package calltouch
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// Outbox регистрирует заявки через исходящую очередь: заявка сначала сохраняется в OutboxStore,
+// затем отправляется в фоне с повторами, поэтому недоступность Calltouch не приводит к потере заявок.
+// Доставка - не менее одного раза, повторная постановка заявки с тем же RequestNumber игнорируется.
+type Outbox struct {
+	client *Client
+	siteID int
+	store  OutboxStore
+	wake   chan struct{}
+
+	MaxAttempts    int           // Попыток до перевода в dead letters. По умолчанию 10.
+	InitialBackoff time.Duration // Пауза после первой неудачи. По умолчанию 10 секунд.
+	MaxBackoff     time.Duration // Максимальная пауза между попытками. По умолчанию 30 минут.
+	PollInterval   time.Duration // Интервал проверки очереди. По умолчанию 5 секунд.
+}
+
+func NewOutbox(client *Client, siteID int, store OutboxStore) *Outbox {
+	return &Outbox{
+		client:         client,
+		siteID:         siteID,
+		store:          store,
+		wake:           make(chan struct{}, 1),
+		MaxAttempts:    10,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     30 * time.Minute,
+		PollInterval:   5 * time.Second,
+	}
+}
+
+// Enqueue сохраняет заявку в очереди. RequestNumber обязателен: он используется для дедупликации.
+// Если заявка с таким номером уже есть в очереди в любом состоянии, вызов ничего не делает.
+func (o *Outbox) Enqueue(ctx context.Context, lead LeadRequest) error {
+	if lead.RequestNumber == "" {
+		return &ValidationError{Field: "requestNumber", Reason: "is required for outbox deduplication"}
+	}
+
+	err := lead.Validate()
+	if err != nil {
+		return err
+	}
+
+	_, ok, err := o.store.Get(ctx, lead.RequestNumber)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+
+	now := time.Now()
+
+	err = o.store.Save(ctx, OutboxEntry{
+		ID:          lead.RequestNumber,
+		State:       OutboxPending,
+		Lead:        lead,
+		NextAttempt: now,
+		CreatedAt:   now,
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Run отправляет заявки из очереди до отмены ctx.
+func (o *Outbox) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := o.Flush(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Println(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// Flush однократно отправляет все заявки, время следующей попытки которых наступило.
+func (o *Outbox) Flush(ctx context.Context) error {
+	pending, err := o.store.List(ctx, OutboxPending)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, entry := range pending {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if entry.NextAttempt.After(now) {
+			continue
+		}
+
+		err = o.store.Save(ctx, o.deliver(ctx, entry))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *Outbox) deliver(ctx context.Context, entry OutboxEntry) OutboxEntry {
+	entry.Attempts++
+
+	requestID, err := o.client.RegisterLead(ctx, o.siteID, entry.Lead)
+	if err == nil {
+		entry.State = OutboxDelivered
+		entry.RequestID = requestID
+		entry.LastError = ""
+		entry.DeliveredAt = time.Now()
+
+		return entry
+	}
+
+	entry.LastError = err.Error()
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) || errors.Is(err, ErrUnauthorized) || entry.Attempts >= o.MaxAttempts {
+		entry.State = OutboxDead
+
+		return entry
+	}
+
+	backoff := o.InitialBackoff << (entry.Attempts - 1)
+	if backoff > o.MaxBackoff || backoff <= 0 {
+		backoff = o.MaxBackoff
+	}
+	entry.NextAttempt = time.Now().Add(backoff)
+
+	return entry
+}
+
+// Depth возвращает количество заявок, ожидающих отправки.
+func (o *Outbox) Depth(ctx context.Context) (int, error) {
+	pending, err := o.store.List(ctx, OutboxPending)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(pending), nil
+}
+
+// DeadLetters возвращает заявки, которые не удалось доставить.
+func (o *Outbox) DeadLetters(ctx context.Context) ([]OutboxEntry, error) {
+	return o.store.List(ctx, OutboxDead)
+}
+
+// Requeue возвращает заявку из dead letters в очередь с обнуленным счетчиком попыток.
+func (o *Outbox) Requeue(ctx context.Context, id string) error {
+	entry, ok, err := o.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok || entry.State != OutboxDead {
+		return ErrNotFound
+	}
+
+	entry.State = OutboxPending
+	entry.Attempts = 0
+	entry.NextAttempt = time.Now()
+
+	err = o.store.Save(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+package calltouch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultOutboxRetention - сколько FileOutboxStore хранит доставленные записи для дедупликации повторных Enqueue.
+const DefaultOutboxRetention = 7 * 24 * time.Hour
+
+// outboxCompactMinLines - минимальное число строк журнала, после которого имеет смысл уплотнение.
+const outboxCompactMinLines = 1000
+
+type OutboxState string
+
+const (
+	OutboxPending   OutboxState = "pending"   // Ожидает отправки.
+	OutboxDelivered OutboxState = "delivered" // Заявка зарегистрирована в Calltouch.
+	OutboxDead      OutboxState = "dead"      // Попытки исчерпаны или заявка отклонена API.
+)
+
+// OutboxEntry - заявка в исходящей очереди. ID совпадает с RequestNumber заявки.
+type OutboxEntry struct {
+	ID          string      `json:"id"`
+	State       OutboxState `json:"state"`
+	Lead        LeadRequest `json:"lead"`
+	Attempts    int         `json:"attempts"`
+	NextAttempt time.Time   `json:"nextAttempt"`
+	LastError   string      `json:"lastError,omitempty"`
+	RequestID   int         `json:"requestId,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	DeliveredAt time.Time   `json:"deliveredAt"`
+}
+
+// OutboxStore хранит записи исходящей очереди заявок.
+type OutboxStore interface {
+	// Save создает или заменяет запись с entry.ID.
+	Save(ctx context.Context, entry OutboxEntry) error
+	Get(ctx context.Context, id string) (entry OutboxEntry, ok bool, err error)
+	// List возвращает записи в состоянии state в порядке создания.
+	List(ctx context.Context, state OutboxState) ([]OutboxEntry, error)
+}
+
+// MemoryOutboxStore хранит очередь в памяти процесса. Подходит для тестов: записи теряются при перезапуске.
+type MemoryOutboxStore struct {
+	mu      sync.Mutex
+	entries map[string]OutboxEntry
+}
+
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{
+		entries: make(map[string]OutboxEntry),
+	}
+}
+
+func (s *MemoryOutboxStore) Save(_ context.Context, entry OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[entry.ID] = entry
+
+	return nil
+}
+
+func (s *MemoryOutboxStore) Get(_ context.Context, id string) (OutboxEntry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+
+	return entry, ok, nil
+}
+
+func (s *MemoryOutboxStore) List(_ context.Context, state OutboxState) ([]OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return filterOutbox(s.entries, state), nil
+}
+
+// FileOutboxStore хранит очередь в append-only журнале: каждая запись Save дописывает строку JSON
+// и синхронизируется на диск. При открытии журнал проигрывается, последняя версия записи побеждает.
+// Журнал уплотняется при открытии и когда устаревших строк становится больше, чем живых записей:
+// файл переписывается только последними версиями записей, а доставленные записи старше срока хранения удаляются.
+// Пока доставленная запись хранится, повторная постановка заявки с тем же номером игнорируется.
+type FileOutboxStore struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	lines   int
+	entries map[string]OutboxEntry
+
+	retention time.Duration
+}
+
+// OpenFileOutboxStore открывает журнал очереди. retention - срок хранения доставленных записей,
+// 0 - DefaultOutboxRetention. Он должен быть не меньше периода, в котором возможна повторная постановка заявки.
+func OpenFileOutboxStore(path string, retention time.Duration) (*FileOutboxStore, error) {
+	if retention <= 0 {
+		retention = DefaultOutboxRetention
+	}
+
+	entries := make(map[string]OutboxEntry)
+
+	f, err := os.Open(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err == nil {
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+		for scanner.Scan() {
+			var entry OutboxEntry
+
+			// Недописанная последняя строка после аварийного завершения пропускается.
+			if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+				continue
+			}
+
+			entries[entry.ID] = entry
+		}
+
+		err = scanner.Err()
+		f.Close()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s := &FileOutboxStore{
+		path:      path,
+		entries:   entries,
+		retention: retention,
+	}
+
+	err = s.compact()
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileOutboxStore) Save(_ context.Context, entry OutboxEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Перевод строки пишется перед записью, чтобы недописанная строка не склеилась со следующей.
+	_, err = s.file.Write(append([]byte("\n"), line...))
+	if err != nil {
+		return err
+	}
+
+	err = s.file.Sync()
+	if err != nil {
+		return err
+	}
+
+	s.entries[entry.ID] = entry
+	s.lines++
+
+	if s.lines > outboxCompactMinLines && s.lines > 2*len(s.entries) {
+		return s.compact()
+	}
+
+	return nil
+}
+
+// Compact удаляет доставленные записи старше срока хранения и переписывает журнал только живыми записями.
+func (s *FileOutboxStore) Compact(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.compact()
+}
+
+func (s *FileOutboxStore) compact() error {
+	cutoff := time.Now().Add(-s.retention)
+	for id, entry := range s.entries {
+		if entry.State != OutboxDelivered {
+			continue
+		}
+
+		delivered := entry.DeliveredAt
+		if delivered.IsZero() {
+			delivered = entry.CreatedAt
+		}
+
+		if delivered.Before(cutoff) {
+			delete(s.entries, id)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	err = writeOutboxLog(tmp, s.entries)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), s.path)
+	if err != nil {
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if s.file != nil {
+		s.file.Close()
+	}
+
+	s.file = file
+	s.lines = len(s.entries)
+
+	return nil
+}
+
+func writeOutboxLog(f *os.File, entries map[string]OutboxEntry) error {
+	w := bufio.NewWriter(f)
+
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(append([]byte("\n"), line...))
+		if err != nil {
+			return err
+		}
+	}
+
+	err := w.Flush()
+	if err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+func (s *FileOutboxStore) Get(_ context.Context, id string) (OutboxEntry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+
+	return entry, ok, nil
+}
+
+func (s *FileOutboxStore) List(_ context.Context, state OutboxState) ([]OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return filterOutbox(s.entries, state), nil
+}
+
+func (s *FileOutboxStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+func filterOutbox(entries map[string]OutboxEntry, state OutboxState) []OutboxEntry {
+	result := make([]OutboxEntry, 0)
+	for _, entry := range entries {
+		if entry.State == state {
+			result = append(result, entry)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+
+	return result
+}
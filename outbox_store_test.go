@@ -0,0 +1,57 @@
+package calltouch_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+)
+
+func TestFileOutboxStoreRetention(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	now := time.Now()
+
+	store, err := calltouch.OpenFileOutboxStore(path, 60*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := []calltouch.OutboxEntry{
+		{ID: "old", State: calltouch.OutboxDelivered, DeliveredAt: now.Add(-90 * 24 * time.Hour)},
+		{ID: "kept", State: calltouch.OutboxDelivered, DeliveredAt: now.Add(-30 * 24 * time.Hour)},
+		{ID: "pending", State: calltouch.OutboxPending, CreatedAt: now.Add(-90 * 24 * time.Hour)},
+		{ID: "dead", State: calltouch.OutboxDead, CreatedAt: now.Add(-90 * 24 * time.Hour)},
+	}
+	for _, entry := range entries {
+		if err := store.Save(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = calltouch.OpenFileOutboxStore(path, 60*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	want := map[string]bool{"old": false, "kept": true, "pending": true, "dead": true}
+	for id, wantOK := range want {
+		_, ok, err := store.Get(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ok != wantOK {
+			t.Errorf("Get(%q) found = %v, want %v", id, ok, wantOK)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Package phone нормализует телефонные номера к формату E.164. По умолчанию используются
+// правила России и стран СНГ; правила можно расширить или заменить.
+package phone
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrEmpty          = errors.New("phone: empty number")
+	ErrUnknownCountry = errors.New("phone: unknown country code")
+	ErrInvalidLength  = errors.New("phone: invalid number length")
+)
+
+// Type - подсказка о типе номера, определяемая по префиксу.
+type Type string
+
+const (
+	TypeUnknown  Type = "unknown"
+	TypeMobile   Type = "mobile"
+	TypeFixed    Type = "fixed"
+	TypeTollFree Type = "toll_free"
+)
+
+// Rule - правила нумерации страны.
+type Rule struct {
+	Country          string   // Код страны ISO 3166-1 alpha-2.
+	CountryCode      string   // Телефонный код страны без "+".
+	NationalLength   int      // Длина национального номера без кода страны и префикса выхода на межгород.
+	TrunkPrefix      string   // Префикс выхода на межгород внутри страны, например "8" в России.
+	NationalPrefixes []string // Допустимые первые цифры национального номера; пусто - любые. Различает страны с общим кодом.
+	MobilePrefixes   []string
+	TollFreePrefixes []string
+}
+
+// DefaultRules возвращает правила России и стран СНГ. Каждый вызов создает новые срезы, их можно изменять.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Country: "KZ", CountryCode: "7", NationalLength: 10, TrunkPrefix: "8", NationalPrefixes: []string{"6", "7"},
+			MobilePrefixes: []string{"70", "747", "75", "76", "77"}, TollFreePrefixes: []string{"800"}},
+		{Country: "RU", CountryCode: "7", NationalLength: 10, TrunkPrefix: "8", NationalPrefixes: []string{"3", "4", "8", "9"},
+			MobilePrefixes: []string{"9"}, TollFreePrefixes: []string{"800"}},
+		{Country: "BY", CountryCode: "375", NationalLength: 9, TrunkPrefix: "80",
+			MobilePrefixes: []string{"25", "29", "33", "44"}, TollFreePrefixes: []string{"800"}},
+		{Country: "UA", CountryCode: "380", NationalLength: 9, TrunkPrefix: "0",
+			MobilePrefixes:   []string{"39", "50", "63", "66", "67", "68", "73", "91", "92", "93", "94", "95", "96", "97", "98", "99"},
+			TollFreePrefixes: []string{"800"}},
+		{Country: "UZ", CountryCode: "998", NationalLength: 9,
+			MobilePrefixes: []string{"33", "50", "55", "77", "88", "90", "91", "93", "94", "95", "97", "98", "99"}},
+		{Country: "KG", CountryCode: "996", NationalLength: 9, TrunkPrefix: "0",
+			MobilePrefixes: []string{"22", "5", "70", "77", "99"}},
+		{Country: "TJ", CountryCode: "992", NationalLength: 9, TrunkPrefix: "8",
+			MobilePrefixes: []string{"0", "11", "5", "9"}},
+		{Country: "AM", CountryCode: "374", NationalLength: 8, TrunkPrefix: "0",
+			MobilePrefixes: []string{"33", "4", "55", "77", "9"}},
+		{Country: "AZ", CountryCode: "994", NationalLength: 9, TrunkPrefix: "0",
+			MobilePrefixes: []string{"10", "40", "50", "51", "55", "60", "70", "77", "99"}},
+		{Country: "GE", CountryCode: "995", NationalLength: 9, TrunkPrefix: "0",
+			MobilePrefixes: []string{"5"}},
+		{Country: "MD", CountryCode: "373", NationalLength: 8, TrunkPrefix: "0",
+			MobilePrefixes: []string{"6", "7"}},
+	}
+}
+
+// Number - разобранный номер.
+type Number struct {
+	E164     string // Номер в формате E.164, например +79991234567.
+	Country  string // Код страны ISO 3166-1 alpha-2.
+	National string // Национальный номер без кода страны.
+	Type     Type
+}
+
+// Normalizer разбирает номера по набору правил. Номера без кода страны считаются номерами страны по умолчанию.
+type Normalizer struct {
+	rules          []Rule
+	defaultCountry string
+}
+
+// New создает Normalizer. Если rules пусты, используются DefaultRules.
+// Правила копируются, поэтому их последующее изменение не влияет на Normalizer.
+func New(defaultCountry string, rules ...Rule) *Normalizer {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+
+	sorted := make([]Rule, len(rules))
+	for i, rule := range rules {
+		rule.NationalPrefixes = append([]string(nil), rule.NationalPrefixes...)
+		rule.MobilePrefixes = append([]string(nil), rule.MobilePrefixes...)
+		rule.TollFreePrefixes = append([]string(nil), rule.TollFreePrefixes...)
+		sorted[i] = rule
+	}
+
+	// Длинные коды страны проверяются первыми, чтобы 375 не перехватывался кодом 3.
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].CountryCode) > len(sorted[j].CountryCode) })
+
+	return &Normalizer{
+		rules:          sorted,
+		defaultCountry: strings.ToUpper(defaultCountry),
+	}
+}
+
+// Default возвращает новый Normalizer с правилами DefaultRules и Россией как страной по умолчанию.
+func Default() *Normalizer {
+	return New("RU")
+}
+
+// Parse разбирает номер: "+7 (999) 123-45-67", "89991234567", "9991234567", "00375291234567" и т.п.
+func (n *Normalizer) Parse(s string) (Number, error) {
+	s = strings.TrimSpace(s)
+	international := strings.HasPrefix(s, "+")
+
+	d := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+
+		return -1
+	}, s)
+	if d == "" {
+		return Number{}, ErrEmpty
+	}
+
+	if !international && strings.HasPrefix(d, "00") {
+		international = true
+		d = d[2:]
+	}
+
+	if international {
+		return n.parseInternational(d)
+	}
+
+	for _, rule := range n.rules {
+		if rule.Country != n.defaultCountry {
+			continue
+		}
+
+		if len(d) == rule.NationalLength {
+			if number, ok := n.match(rule.CountryCode, d); ok {
+				return number, nil
+			}
+		}
+
+		if rule.TrunkPrefix != "" && len(d) == len(rule.TrunkPrefix)+rule.NationalLength && strings.HasPrefix(d, rule.TrunkPrefix) {
+			if number, ok := n.match(rule.CountryCode, d[len(rule.TrunkPrefix):]); ok {
+				return number, nil
+			}
+		}
+	}
+
+	// Номер с кодом страны, но без "+", например 79991234567.
+	return n.parseInternational(d)
+}
+
+func (n *Normalizer) parseInternational(d string) (Number, error) {
+	knownCode := false
+
+	for _, rule := range n.rules {
+		if !strings.HasPrefix(d, rule.CountryCode) {
+			continue
+		}
+
+		knownCode = true
+
+		if number, ok := n.match(rule.CountryCode, d[len(rule.CountryCode):]); ok {
+			return number, nil
+		}
+	}
+
+	if knownCode {
+		return Number{}, ErrInvalidLength
+	}
+
+	return Number{}, ErrUnknownCountry
+}
+
+// match выбирает правило для кода страны и национального номера.
+func (n *Normalizer) match(countryCode, national string) (Number, bool) {
+	for _, rule := range n.rules {
+		if rule.CountryCode != countryCode || len(national) != rule.NationalLength {
+			continue
+		}
+
+		if len(rule.NationalPrefixes) > 0 && longestPrefix(national, rule.NationalPrefixes) == "" {
+			continue
+		}
+
+		number := Number{
+			E164:     "+" + countryCode + national,
+			Country:  rule.Country,
+			National: national,
+			Type:     TypeFixed,
+		}
+
+		mobile := longestPrefix(national, rule.MobilePrefixes)
+		tollFree := longestPrefix(national, rule.TollFreePrefixes)
+
+		switch {
+		case tollFree != "" && len(tollFree) >= len(mobile):
+			number.Type = TypeTollFree
+		case mobile != "":
+			number.Type = TypeMobile
+		case len(rule.MobilePrefixes) == 0:
+			number.Type = TypeUnknown
+		}
+
+		return number, true
+	}
+
+	return Number{}, false
+}
+
+// Normalize возвращает номер в формате E.164 или исходную строку, если номер не распознан.
+func (n *Normalizer) Normalize(s string) string {
+	number, err := n.Parse(s)
+	if err != nil {
+		return s
+	}
+
+	return number.E164
+}
+
+// Valid сообщает, распознается ли номер правилами.
+func (n *Normalizer) Valid(s string) bool {
+	_, err := n.Parse(s)
+
+	return err == nil
+}
+
+// Normalize нормализует номер правилами по умолчанию.
+func Normalize(s string) string {
+	return Default().Normalize(s)
+}
+
+func longestPrefix(s string, prefixes []string) string {
+	best := ""
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,185 @@
+package phone_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mg-realcom/calltouch-sdk/phone"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		in      string
+		country string
+		e164    string
+		typ     phone.Type
+	}{
+		// Россия: код 7, префикс 8, национальные номера на 3, 4, 8, 9.
+		{name: "RU international", in: "+7 (999) 123-45-67", country: "RU", e164: "+79991234567", typ: phone.TypeMobile},
+		{name: "RU trunk prefix", in: "8 999 123 45 67", country: "RU", e164: "+79991234567", typ: phone.TypeMobile},
+		{name: "RU national", in: "9991234567", country: "RU", e164: "+79991234567", typ: phone.TypeMobile},
+		{name: "RU without plus", in: "74951234567", country: "RU", e164: "+74951234567", typ: phone.TypeFixed},
+		{name: "RU fixed", in: "8 (495) 123-45-67", country: "RU", e164: "+74951234567", typ: phone.TypeFixed},
+		{name: "RU toll-free", in: "8 800 555-35-35", country: "RU", e164: "+78005553535", typ: phone.TypeTollFree},
+		{name: "RU 00 prefix", in: "00 7 999 123 45 67", country: "RU", e164: "+79991234567", typ: phone.TypeMobile},
+
+		// Казахстан делит код 7 с Россией и различается первой цифрой национального номера.
+		{name: "KZ mobile", in: "+7 701 123 45 67", country: "KZ", e164: "+77011234567", typ: phone.TypeMobile},
+		{name: "KZ mobile 747", in: "+7 747 123 45 67", country: "KZ", e164: "+77471234567", typ: phone.TypeMobile},
+		{name: "KZ fixed", in: "+7 727 123 45 67", country: "KZ", e164: "+77271234567", typ: phone.TypeFixed},
+		{name: "KZ trunk prefix", in: "8 701 123 45 67", country: "KZ", e164: "+77011234567", typ: phone.TypeMobile},
+		{name: "KZ national", in: "7011234567", country: "KZ", e164: "+77011234567", typ: phone.TypeMobile},
+		{name: "KZ fixed 6", in: "+7 612 345 67 89", country: "KZ", e164: "+76123456789", typ: phone.TypeFixed},
+
+		{name: "BY mobile", in: "+375 29 123-45-67", country: "BY", e164: "+375291234567", typ: phone.TypeMobile},
+		{name: "BY fixed", in: "+375 17 123-45-67", country: "BY", e164: "+375171234567", typ: phone.TypeFixed},
+		{name: "BY toll-free", in: "+375 800 123456", country: "BY", e164: "+375800123456", typ: phone.TypeTollFree},
+		{name: "BY 00 prefix", in: "00375291234567", country: "BY", e164: "+375291234567", typ: phone.TypeMobile},
+		{name: "UA mobile", in: "+380 50 123 4567", country: "UA", e164: "+380501234567", typ: phone.TypeMobile},
+		{name: "UA fixed", in: "+380 44 123 4567", country: "UA", e164: "+380441234567", typ: phone.TypeFixed},
+		{name: "UA toll-free", in: "+380 800 123 456", country: "UA", e164: "+380800123456", typ: phone.TypeTollFree},
+		{name: "UZ mobile", in: "+998 90 123 45 67", country: "UZ", e164: "+998901234567", typ: phone.TypeMobile},
+		{name: "UZ fixed", in: "+998 71 123 45 67", country: "UZ", e164: "+998711234567", typ: phone.TypeFixed},
+		{name: "KG mobile", in: "+996 555 123 456", country: "KG", e164: "+996555123456", typ: phone.TypeMobile},
+		{name: "KG fixed", in: "+996 312 123 456", country: "KG", e164: "+996312123456", typ: phone.TypeFixed},
+		{name: "TJ mobile", in: "+992 93 123 4567", country: "TJ", e164: "+992931234567", typ: phone.TypeMobile},
+		{name: "TJ fixed", in: "+992 37 123 4567", country: "TJ", e164: "+992371234567", typ: phone.TypeFixed},
+		{name: "AM mobile", in: "+374 91 123 456", country: "AM", e164: "+37491123456", typ: phone.TypeMobile},
+		{name: "AM fixed", in: "+374 10 123 456", country: "AM", e164: "+37410123456", typ: phone.TypeFixed},
+		{name: "AZ mobile", in: "+994 50 123 45 67", country: "AZ", e164: "+994501234567", typ: phone.TypeMobile},
+		{name: "AZ fixed", in: "+994 12 123 45 67", country: "AZ", e164: "+994121234567", typ: phone.TypeFixed},
+		{name: "GE mobile", in: "+995 555 12 34 56", country: "GE", e164: "+995555123456", typ: phone.TypeMobile},
+		{name: "GE fixed", in: "+995 32 212 34 56", country: "GE", e164: "+995322123456", typ: phone.TypeFixed},
+		{name: "MD mobile", in: "+373 69 123 456", country: "MD", e164: "+37369123456", typ: phone.TypeMobile},
+		{name: "MD fixed", in: "+373 22 123 456", country: "MD", e164: "+37322123456", typ: phone.TypeFixed},
+	}
+
+	n := phone.Default()
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := n.Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.in, err)
+			}
+
+			if got.Country != tt.country || got.E164 != tt.e164 || got.Type != tt.typ {
+				t.Errorf("Parse(%q) = %+v, want %s %s %s", tt.in, got, tt.country, tt.e164, tt.typ)
+			}
+		})
+	}
+}
+
+func TestParseTrunkPrefixOfDefaultCountry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		country string
+		in      string
+		e164    string
+	}{
+		{country: "BY", in: "80 29 123-45-67", e164: "+375291234567"},
+		{country: "BY", in: "291234567", e164: "+375291234567"},
+		{country: "UA", in: "050 123 4567", e164: "+380501234567"},
+		{country: "KG", in: "0555 123 456", e164: "+996555123456"},
+		{country: "TJ", in: "8 93 123 4567", e164: "+992931234567"},
+		{country: "AM", in: "091 123 456", e164: "+37491123456"},
+		{country: "AZ", in: "050 123 45 67", e164: "+994501234567"},
+		{country: "GE", in: "0 555 12 34 56", e164: "+995555123456"},
+		{country: "MD", in: "069 123 456", e164: "+37369123456"},
+		{country: "UZ", in: "90 123 45 67", e164: "+998901234567"},
+		{country: "kz", in: "8 701 123 45 67", e164: "+77011234567"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.country+" "+tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := phone.New(tt.country).Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.in, err)
+			}
+
+			if got.E164 != tt.e164 {
+				t.Errorf("Parse(%q) = %s, want %s", tt.in, got.E164, tt.e164)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{in: "", want: phone.ErrEmpty},
+		{in: "no digits", want: phone.ErrEmpty},
+		{in: "+1 202 555 0100", want: phone.ErrUnknownCountry},
+		{in: "0044 20 7946 0018", want: phone.ErrUnknownCountry},
+		{in: "+7 999 123 45", want: phone.ErrInvalidLength},
+		{in: "+7 599 123 45 67", want: phone.ErrInvalidLength}, // Первая цифра не подходит ни KZ, ни RU.
+		{in: "+375 29 123 45 678", want: phone.ErrInvalidLength},
+	}
+
+	n := phone.Default()
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := n.Parse(tt.in)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	if got := phone.Normalize("8 (999) 123-45-67"); got != "+79991234567" {
+		t.Errorf("Normalize() = %q, want +79991234567", got)
+	}
+
+	if got := phone.Normalize("unknown"); got != "unknown" {
+		t.Errorf("Normalize() of an unparsable number = %q, want it unchanged", got)
+	}
+}
+
+func TestRulesAreCopied(t *testing.T) {
+	t.Parallel()
+
+	rules := phone.DefaultRules()
+	n := phone.New("RU", rules...)
+
+	for i := range rules {
+		rules[i].MobilePrefixes[0] = "0"
+		rules[i].NationalPrefixes = nil
+	}
+
+	phone.DefaultRules()[1].MobilePrefixes[0] = "0"
+
+	got, err := n.Parse("+79991234567")
+	if err != nil || got.Country != "RU" || got.Type != phone.TypeMobile {
+		t.Errorf("Parse() after mutating rules = %+v, %v; want RU mobile", got, err)
+	}
+
+	got, err = phone.Default().Parse("+79991234567")
+	if err != nil || got.Type != phone.TypeMobile {
+		t.Errorf("Default().Parse() after mutating DefaultRules() = %+v, %v; want RU mobile", got, err)
+	}
+}
@@ -0,0 +1,51 @@
+package calltouch
+
+// SetPhoneNormalizer включает нормализацию телефонов при декодировании ответов CallsDiary, Call и LeadsDiary.
+// Например, c.SetPhoneNormalizer(phone.Normalize) приводит номера к формату E.164. nil отключает нормализацию.
+func (c *Client) SetPhoneNormalizer(normalize func(string) string) {
+	c.normalizePhone = normalize
+}
+
+func (c *Client) applyPhoneNormalizer(call *Call) {
+	if c.normalizePhone != nil {
+		call.NormalizePhones(c.normalizePhone)
+	}
+}
+
+// NormalizePhones применяет normalize к CallerNumber, PhoneNumber, RedirectNumber и PhonesInText.
+func (c *Call) NormalizePhones(normalize func(string) string) {
+	c.CallerNumber = normalizeNonEmpty(normalize, c.CallerNumber)
+	c.PhoneNumber = normalizeNonEmpty(normalize, c.PhoneNumber)
+	c.RedirectNumber = normalizeNonEmpty(normalize, c.RedirectNumber)
+
+	if c.PhonesInText != nil {
+		phones := make([]string, len(*c.PhonesInText))
+		for i, p := range *c.PhonesInText {
+			phones[i] = normalizeNonEmpty(normalize, p)
+		}
+
+		c.PhonesInText = &phones
+	}
+}
+
+// NormalizePhones применяет normalize к ClientInfo.Phones[].PhoneNumber.
+func (l *Lead) NormalizePhones(normalize func(string) string) {
+	if l.Client.Phones == nil {
+		return
+	}
+
+	phones := append(l.Client.Phones[:0:0], l.Client.Phones...)
+	for i := range phones {
+		phones[i].PhoneNumber = normalizeNonEmpty(normalize, phones[i].PhoneNumber)
+	}
+
+	l.Client.Phones = phones
+}
+
+func normalizeNonEmpty(normalize func(string) string, s string) string {
+	if s == "" {
+		return s
+	}
+
+	return normalize(s)
+}
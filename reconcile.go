@@ -0,0 +1,180 @@
+package calltouch
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CRMOrder - сделка из CRM, сверяемая со сделками в Calltouch по номеру.
+type CRMOrder struct {
+	OrderNumber string      // Уникальный идентификатор сделки в CRM.
+	OrderName   string      // Название сделки.
+	Amount      float64     // Бюджет сделки в рублях.
+	Status      OrderStatus // Статус сделки.
+	Link        OrderLink   // Связь с лидом, используется при создании отсутствующей сделки.
+}
+
+// CRMOrderIterator последовательно отдает сделки CRM. Next возвращает ok == false, когда сделки закончились.
+type CRMOrderIterator interface {
+	Next() (order CRMOrder, ok bool, err error)
+}
+
+type sliceCRMOrders struct {
+	orders []CRMOrder
+}
+
+func (s *sliceCRMOrders) Next() (CRMOrder, bool, error) {
+	if len(s.orders) == 0 {
+		return CRMOrder{}, false, nil
+	}
+
+	order := s.orders[0]
+	s.orders = s.orders[1:]
+
+	return order, true, nil
+}
+
+// SliceCRMOrders возвращает итератор по срезу сделок.
+func SliceCRMOrders(orders []CRMOrder) CRMOrderIterator {
+	return &sliceCRMOrders{orders: orders}
+}
+
+type DiscrepancyKind string
+
+const (
+	DiscrepancyMissing        DiscrepancyKind = "missing"         // Сделки нет ни у одного звонка или заявки.
+	DiscrepancyAmountMismatch DiscrepancyKind = "amount_mismatch" // Сумма в Calltouch отличается от CRM.
+	DiscrepancyStatusMismatch DiscrepancyKind = "status_mismatch" // Статус в Calltouch отличается от CRM.
+)
+
+// Discrepancy - расхождение между CRM и Calltouch по одной сделке.
+type Discrepancy struct {
+	Kind            DiscrepancyKind
+	CRM             CRMOrder
+	CalltouchAmount float64
+	CalltouchStatus OrderStatus
+	Applied         bool  // Исправление отправлено в Calltouch (только в режиме ReconcileApply).
+	Err             error // Ошибка исправления.
+}
+
+type ReconcileMode int
+
+const (
+	ReconcileDryRun ReconcileMode = iota // Только отчет о расхождениях.
+	ReconcileApply                       // Отчет и исправление через API сделок.
+)
+
+// Reconciler сверяет сделки CRM со сделками, привязанными к звонкам и заявкам.
+type Reconciler struct {
+	client *Client
+	siteID int
+	mode   ReconcileMode
+}
+
+func NewReconciler(client *Client, siteID int, mode ReconcileMode) *Reconciler {
+	return &Reconciler{
+		client: client,
+		siteID: siteID,
+		mode:   mode,
+	}
+}
+
+type calltouchOrder struct {
+	amount      float64
+	amountKnown bool // false, если сумму сделки заявки не удалось разобрать: суммы тогда не сверяются.
+	status      OrderStatus
+}
+
+// Reconcile сверяет сделки CRM со сделками из calls и leads (выгруженных с withOrders) и возвращает расхождения.
+// В режиме ReconcileApply отсутствующие сделки создаются, а расхождения исправляются по данным CRM.
+// Ошибки исправления записываются в Discrepancy.Err, ошибка возвращается только при сбое итератора.
+func (r *Reconciler) Reconcile(ctx context.Context, crm CRMOrderIterator, calls []Call, leads []Lead) ([]Discrepancy, error) {
+	known := make(map[string]calltouchOrder)
+
+	for _, call := range calls {
+		for _, order := range call.Orders {
+			amount := order.CompletedAmount
+			if amount == 0 {
+				amount = order.PlannedAmount
+			}
+
+			known[order.OrderNumber] = calltouchOrder{amount: float64(amount), amountKnown: true, status: known[order.OrderNumber].status}
+		}
+	}
+
+	for _, lead := range leads {
+		for _, order := range lead.Orders {
+			amount, pErr := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(order.Sum), ",", "."), 64)
+
+			known[order.OrderNumber] = calltouchOrder{amount: amount, amountKnown: pErr == nil, status: OrderStatus(order.Status)}
+		}
+	}
+
+	discrepancies := make([]Discrepancy, 0)
+
+	for {
+		order, ok, err := crm.Next()
+		if err != nil {
+			return discrepancies, err
+		}
+		if !ok {
+			break
+		}
+
+		found, exists := known[order.OrderNumber]
+		if !exists {
+			discrepancies = append(discrepancies, r.fix(ctx, Discrepancy{Kind: DiscrepancyMissing, CRM: order}))
+
+			continue
+		}
+
+		if found.amountKnown && math.Abs(found.amount-order.Amount) >= 0.01 {
+			discrepancies = append(discrepancies, r.fix(ctx, Discrepancy{
+				Kind:            DiscrepancyAmountMismatch,
+				CRM:             order,
+				CalltouchAmount: found.amount,
+				CalltouchStatus: found.status,
+			}))
+		}
+
+		if found.status != "" && order.Status != "" && !strings.EqualFold(string(found.status), string(order.Status)) {
+			discrepancies = append(discrepancies, r.fix(ctx, Discrepancy{
+				Kind:            DiscrepancyStatusMismatch,
+				CRM:             order,
+				CalltouchAmount: found.amount,
+				CalltouchStatus: found.status,
+			}))
+		}
+	}
+
+	return discrepancies, nil
+}
+
+func (r *Reconciler) fix(ctx context.Context, d Discrepancy) Discrepancy {
+	if r.mode != ReconcileApply {
+		return d
+	}
+
+	amount := d.CRM.Amount
+
+	switch d.Kind {
+	case DiscrepancyMissing:
+		_, d.Err = r.client.CreateOrder(ctx, r.siteID, OrderRequest{
+			OrderNumber: d.CRM.OrderNumber,
+			OrderName:   d.CRM.OrderName,
+			Status:      d.CRM.Status,
+			Amount:      &amount,
+			Link:        d.CRM.Link,
+		})
+	case DiscrepancyAmountMismatch:
+		d.Err = r.client.UpdateOrder(ctx, r.siteID, OrderRequest{OrderNumber: d.CRM.OrderNumber, Amount: &amount})
+	case DiscrepancyStatusMismatch:
+		d.Err = r.client.SetOrderStatus(ctx, r.siteID, d.CRM.OrderNumber, d.CRM.Status)
+	}
+
+	d.Applied = d.Err == nil
+
+	return d
+}
@@ -0,0 +1,51 @@
+package calltouch_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+)
+
+func TestReconcileDryRun(t *testing.T) {
+	t.Parallel()
+
+	calls := []calltouch.Call{{Orders: []calltouch.CallOrder{
+		{OrderNumber: "c1", PlannedAmount: 100},
+		{OrderNumber: "c2", PlannedAmount: 100, CompletedAmount: 150},
+	}}}
+	leads := []calltouch.Lead{{Orders: []calltouch.LeadOrder{
+		{OrderNumber: "l1", Sum: "100,50", Status: "paid"},
+		{OrderNumber: "l2", Sum: "90", Status: "paid"},
+		{OrderNumber: "l3", Sum: "n/a", Status: "paid"},
+		{OrderNumber: "l4", Sum: "", Status: "new"},
+	}}}
+	crm := []calltouch.CRMOrder{
+		{OrderNumber: "c1", Amount: 100},
+		{OrderNumber: "c2", Amount: 100},
+		{OrderNumber: "l1", Amount: 100.5, Status: calltouch.OrderStatusPaid},
+		{OrderNumber: "l2", Amount: 100, Status: calltouch.OrderStatusPaid},
+		{OrderNumber: "l3", Amount: 100, Status: calltouch.OrderStatusPaid},
+		{OrderNumber: "l4", Amount: 100, Status: calltouch.OrderStatusPaid},
+		{OrderNumber: "x", Amount: 0},
+	}
+
+	r := calltouch.NewReconciler(nil, 1, calltouch.ReconcileDryRun)
+
+	got, err := r.Reconcile(context.Background(), calltouch.SliceCRMOrders(crm), calls, leads)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []calltouch.Discrepancy{
+		{Kind: calltouch.DiscrepancyAmountMismatch, CRM: crm[1], CalltouchAmount: 150},
+		{Kind: calltouch.DiscrepancyAmountMismatch, CRM: crm[3], CalltouchAmount: 90, CalltouchStatus: calltouch.OrderStatusPaid},
+		{Kind: calltouch.DiscrepancyStatusMismatch, CRM: crm[5], CalltouchStatus: calltouch.OrderStatusNew},
+		{Kind: calltouch.DiscrepancyMissing, CRM: crm[6]},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reconcile() = %+v, want %+v", got, want)
+	}
+}
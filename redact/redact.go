@@ -0,0 +1,329 @@
+// Package redact удаляет персональные данные (152-ФЗ) из звонков, заявок и текстов разговоров:
+// телефоны, адреса почты, номера карт и имена.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+	"github.com/mg-realcom/calltouch-sdk/phone"
+)
+
+// Action - способ обработки поля.
+type Action int
+
+const (
+	Keep Action = iota // Оставить без изменений.
+	Drop               // Удалить значение.
+	Mask               // Заменить маской: у телефона остаются последние две цифры, у почты - домен.
+	Hash               // Заменить ключевым хешем HMAC-SHA256: одинаковые значения дают одинаковый хеш.
+)
+
+// Field - обрабатываемое поле звонка или заявки.
+type Field string
+
+const (
+	FieldPhrases        Field = "phrases"        // Call.Phrases[].Message.
+	FieldCallerNumber   Field = "callerNumber"   // Call.CallerNumber.
+	FieldIP             Field = "ip"             // Call.IP, Lead.Session.IP.
+	FieldFio            Field = "fio"            // Lead.Client.Fio.
+	FieldPhones         Field = "phones"         // Lead.Client.Phones[].PhoneNumber.
+	FieldContacts       Field = "contacts"       // Lead.Client.Contacts[].ContactValue.
+	FieldCallbackFields Field = "callbackFields" // Call.CallbackInfo.Fields[].Value.
+	FieldPhonesInText   Field = "phonesInText"   // Call.PhonesInText.
+)
+
+// Redactor обрабатывает поля по заданным действиям. Поля без действия остаются без изменений.
+type Redactor struct {
+	key     []byte
+	actions map[Field]Action
+	phones  *phone.Normalizer
+
+	emailPattern *regexp.Regexp
+	cardPattern  *regexp.Regexp
+	phonePattern *regexp.Regexp
+	namePattern  *regexp.Regexp
+}
+
+// New создает Redactor. key - секрет для Hash: без него хеши телефонов можно подобрать перебором.
+func New(key []byte, actions map[Field]Action) *Redactor {
+	copied := make(map[Field]Action, len(actions))
+	for field, action := range actions {
+		copied[field] = action
+	}
+
+	return &Redactor{
+		key:          key,
+		actions:      copied,
+		phones:       phone.Default(),
+		emailPattern: regexp.MustCompile(`[\p{L}\d._%+\-]+@[\p{L}\d.\-]+\.\p{L}{2,}`),
+		cardPattern:  regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		phonePattern: regexp.MustCompile(`(?:\+\s?)?\d[\d\s\-()]{8,16}\d`),
+		namePattern:  regexp.MustCompile(`(?i)(меня зовут|моё имя|мое имя|my name is)\s+(\p{Lu}\p{Ll}+)`),
+	}
+}
+
+// Call возвращает копию звонка с обработанными полями. Исходный звонок не изменяется.
+func (r *Redactor) Call(call calltouch.Call) calltouch.Call {
+	call.CallerNumber = r.phone(FieldCallerNumber, call.CallerNumber)
+	call.IP = r.value(FieldIP, call.IP)
+
+	names := make([]string, 0)
+
+	if len(call.CallbackInfo.Fields) > 0 {
+		fields := make([]calltouch.ValueField, len(call.CallbackInfo.Fields))
+		for i, field := range call.CallbackInfo.Fields {
+			names = append(names, nameTokens(field)...)
+
+			field.Value = r.value(FieldCallbackFields, field.Value)
+			fields[i] = field
+		}
+
+		call.CallbackInfo.Fields = fields
+	}
+
+	if call.PhonesInText != nil {
+		if r.actions[FieldPhonesInText] == Drop {
+			call.PhonesInText = nil
+		} else {
+			phones := make([]string, len(*call.PhonesInText))
+			for i, number := range *call.PhonesInText {
+				phones[i] = r.phone(FieldPhonesInText, number)
+			}
+
+			call.PhonesInText = &phones
+		}
+	}
+
+	if call.Phrases != nil && r.actions[FieldPhrases] != Keep {
+		phrases := make([]calltouch.Phrase, len(*call.Phrases))
+		for i, phrase := range *call.Phrases {
+			phrase.Message = r.text(FieldPhrases, phrase.Message, names)
+			phrases[i] = phrase
+		}
+
+		call.Phrases = &phrases
+	}
+
+	return call
+}
+
+// Lead возвращает копию заявки с обработанными полями. Исходная заявка не изменяется.
+func (r *Redactor) Lead(lead calltouch.Lead) calltouch.Lead {
+	lead.Client.Fio = r.value(FieldFio, lead.Client.Fio)
+	lead.Session.IP = r.value(FieldIP, lead.Session.IP)
+
+	if lead.Client.Phones != nil {
+		phones := append(lead.Client.Phones[:0:0], lead.Client.Phones...)
+		for i := range phones {
+			phones[i].PhoneNumber = r.phone(FieldPhones, phones[i].PhoneNumber)
+		}
+
+		lead.Client.Phones = phones
+	}
+
+	if lead.Client.Contacts != nil {
+		contacts := append(lead.Client.Contacts[:0:0], lead.Client.Contacts...)
+		for i := range contacts {
+			contacts[i].ContactValue = r.value(FieldContacts, contacts[i].ContactValue)
+		}
+
+		lead.Client.Contacts = contacts
+	}
+
+	return lead
+}
+
+// Text заменяет в произвольном тексте телефоны, почту, номера карт и имена согласно действию для FieldPhrases.
+// names - дополнительные имена, которые нужно скрыть, например ФИО клиента.
+func (r *Redactor) Text(s string, names ...string) string {
+	return r.text(FieldPhrases, s, names)
+}
+
+func (r *Redactor) text(field Field, s string, names []string) string {
+	action := r.actions[field]
+
+	switch action {
+	case Keep:
+		return s
+	case Drop:
+		return ""
+	}
+
+	// Все шаблоны ищутся в исходном тексте, а замены выполняются за один проход: иначе следующий шаблон
+	// находил бы цифры внутри уже подставленных хешей. При пересечении побеждает фрагмент, найденный раньше.
+	spans := make([]span, 0)
+	claim := func(start, end int, kind string, normalize func(string) string) {
+		for _, sp := range spans {
+			if start < sp.end && sp.start < end {
+				return
+			}
+		}
+
+		replacement := "[" + kind + "]"
+		if action == Hash {
+			replacement = "[" + kind + ":" + r.hash(normalize(s[start:end])) + "]"
+		}
+
+		spans = append(spans, span{start: start, end: end, replacement: replacement})
+	}
+
+	for _, m := range r.emailPattern.FindAllStringIndex(s, -1) {
+		claim(m[0], m[1], "email", strings.ToLower)
+	}
+
+	for _, m := range r.cardPattern.FindAllStringIndex(s, -1) {
+		if luhn(digits(s[m[0]:m[1]])) {
+			claim(m[0], m[1], "card", digits)
+		}
+	}
+
+	for _, m := range r.phonePattern.FindAllStringIndex(s, -1) {
+		if n := len(digits(s[m[0]:m[1]])); n >= 10 && n <= 15 {
+			claim(m[0], m[1], "phone", r.normalizePhone)
+		}
+	}
+
+	for _, m := range r.namePattern.FindAllStringSubmatchIndex(s, -1) {
+		claim(m[4], m[5], "name", strings.ToLower)
+	}
+
+	for _, name := range names {
+		if len([]rune(name)) < 2 {
+			continue
+		}
+
+		// \b в regexp учитывает только ASCII, поэтому границы слова для кириллицы задаются явно.
+		pattern := regexp.MustCompile(`(?i)(^|[^\p{L}\p{N}])(` + regexp.QuoteMeta(name) + `)([^\p{L}\p{N}]|$)`)
+		for _, m := range pattern.FindAllStringSubmatchIndex(s, -1) {
+			claim(m[4], m[5], "name", strings.ToLower)
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var b strings.Builder
+
+	last := 0
+	for _, sp := range spans {
+		b.WriteString(s[last:sp.start])
+		b.WriteString(sp.replacement)
+		last = sp.end
+	}
+	b.WriteString(s[last:])
+
+	return b.String()
+}
+
+// span - найденный в тексте фрагмент с персональными данными и его замена.
+type span struct {
+	start, end  int
+	replacement string
+}
+
+func (r *Redactor) value(field Field, s string) string {
+	if s == "" {
+		return s
+	}
+
+	switch r.actions[field] {
+	case Drop:
+		return ""
+	case Mask:
+		if at := strings.LastIndex(s, "@"); at > 0 {
+			return "***" + s[at:]
+		}
+
+		return "***"
+	case Hash:
+		return r.hash(strings.ToLower(strings.TrimSpace(s)))
+	default:
+		return s
+	}
+}
+
+func (r *Redactor) phone(field Field, s string) string {
+	if s == "" {
+		return s
+	}
+
+	switch r.actions[field] {
+	case Drop:
+		return ""
+	case Mask:
+		d := digits(s)
+		if len(d) <= 2 {
+			return "***"
+		}
+
+		return strings.Repeat("*", len(d)-2) + d[len(d)-2:]
+	case Hash:
+		return r.hash(r.normalizePhone(s))
+	default:
+		return s
+	}
+}
+
+func (r *Redactor) hash(s string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(s))
+
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// normalizePhone приводит номер к E.164, чтобы хеши разных записей одного номера совпадали.
+// Нераспознанные номера сводятся к цифрам.
+func (r *Redactor) normalizePhone(s string) string {
+	number, err := r.phones.Parse(s)
+	if err != nil {
+		return digits(s)
+	}
+
+	return number.E164
+}
+
+// nameTokens возвращает значение поля формы обратного звонка, если это поле с именем.
+func nameTokens(field calltouch.ValueField) []string {
+	name := strings.ToLower(field.Name)
+	if !strings.Contains(name, "name") && !strings.Contains(name, "имя") && !strings.Contains(name, "фио") && !strings.Contains(name, "fio") {
+		return nil
+	}
+
+	return strings.Fields(field.Value)
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+
+		return -1
+	}, s)
+}
+
+func luhn(number string) bool {
+	sum := 0
+	double := false
+
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return len(number) >= 13 && sum%10 == 0
+}
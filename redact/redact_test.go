@@ -0,0 +1,242 @@
+package redact_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+	"github.com/mg-realcom/calltouch-sdk/redact"
+)
+
+const testKey = "secret"
+
+func hash(s string) string {
+	mac := hmac.New(sha256.New, []byte(testKey))
+	mac.Write([]byte(s))
+
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+func testCall() calltouch.Call {
+	phrases := []calltouch.Phrase{{Message: "позвоните 8 999 123 45 67"}}
+	phones := []string{"89991234567"}
+
+	return calltouch.Call{
+		CallerNumber: "+7 (999) 123-45-67",
+		IP:           "10.0.0.1",
+		Phrases:      &phrases,
+		PhonesInText: &phones,
+		CallbackInfo: calltouch.CallBackInfo{Fields: []calltouch.ValueField{{Name: "name", Value: "Иван"}}},
+	}
+}
+
+func testLead(t *testing.T) calltouch.Lead {
+	t.Helper()
+
+	var lead calltouch.Lead
+
+	err := json.Unmarshal([]byte(`{
+		"session": {"ip": "10.0.0.1"},
+		"client": {
+			"fio": "Иван Петров",
+			"phones": [{"phoneNumber": "8 999 123 45 67"}],
+			"contacts": [{"contactType": "EMAIL", "contactValue": "User@Mail.ru"}]
+		}
+	}`), &lead)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return lead
+}
+
+func TestRedactorFields(t *testing.T) {
+	t.Parallel()
+
+	callerNumber := func(c calltouch.Call, _ calltouch.Lead) string { return c.CallerNumber }
+	callIP := func(c calltouch.Call, _ calltouch.Lead) string { return c.IP }
+	leadIP := func(_ calltouch.Call, l calltouch.Lead) string { return l.Session.IP }
+	fio := func(_ calltouch.Call, l calltouch.Lead) string { return l.Client.Fio }
+	phones := func(_ calltouch.Call, l calltouch.Lead) string { return l.Client.Phones[0].PhoneNumber }
+	contacts := func(_ calltouch.Call, l calltouch.Lead) string { return l.Client.Contacts[0].ContactValue }
+	callback := func(c calltouch.Call, _ calltouch.Lead) string { return c.CallbackInfo.Fields[0].Value }
+	phrases := func(c calltouch.Call, _ calltouch.Lead) string { return (*c.Phrases)[0].Message }
+	phonesInText := func(c calltouch.Call, _ calltouch.Lead) string {
+		if c.PhonesInText == nil {
+			return "<nil>"
+		}
+
+		return (*c.PhonesInText)[0]
+	}
+
+	tests := []struct {
+		field  redact.Field
+		action redact.Action
+		get    func(calltouch.Call, calltouch.Lead) string
+		want   string
+	}{
+		{redact.FieldCallerNumber, redact.Keep, callerNumber, "+7 (999) 123-45-67"},
+		{redact.FieldCallerNumber, redact.Drop, callerNumber, ""},
+		{redact.FieldCallerNumber, redact.Mask, callerNumber, "*********67"},
+		{redact.FieldCallerNumber, redact.Hash, callerNumber, hash("+79991234567")},
+
+		{redact.FieldIP, redact.Keep, callIP, "10.0.0.1"},
+		{redact.FieldIP, redact.Drop, callIP, ""},
+		{redact.FieldIP, redact.Mask, callIP, "***"},
+		{redact.FieldIP, redact.Hash, callIP, hash("10.0.0.1")},
+		{redact.FieldIP, redact.Keep, leadIP, "10.0.0.1"},
+		{redact.FieldIP, redact.Drop, leadIP, ""},
+		{redact.FieldIP, redact.Mask, leadIP, "***"},
+		{redact.FieldIP, redact.Hash, leadIP, hash("10.0.0.1")},
+
+		{redact.FieldFio, redact.Keep, fio, "Иван Петров"},
+		{redact.FieldFio, redact.Drop, fio, ""},
+		{redact.FieldFio, redact.Mask, fio, "***"},
+		{redact.FieldFio, redact.Hash, fio, hash("иван петров")},
+
+		{redact.FieldPhones, redact.Keep, phones, "8 999 123 45 67"},
+		{redact.FieldPhones, redact.Drop, phones, ""},
+		{redact.FieldPhones, redact.Mask, phones, "*********67"},
+		{redact.FieldPhones, redact.Hash, phones, hash("+79991234567")},
+
+		{redact.FieldContacts, redact.Keep, contacts, "User@Mail.ru"},
+		{redact.FieldContacts, redact.Drop, contacts, ""},
+		{redact.FieldContacts, redact.Mask, contacts, "***@Mail.ru"},
+		{redact.FieldContacts, redact.Hash, contacts, hash("user@mail.ru")},
+
+		{redact.FieldCallbackFields, redact.Keep, callback, "Иван"},
+		{redact.FieldCallbackFields, redact.Drop, callback, ""},
+		{redact.FieldCallbackFields, redact.Mask, callback, "***"},
+		{redact.FieldCallbackFields, redact.Hash, callback, hash("иван")},
+
+		{redact.FieldPhonesInText, redact.Keep, phonesInText, "89991234567"},
+		{redact.FieldPhonesInText, redact.Drop, phonesInText, "<nil>"},
+		{redact.FieldPhonesInText, redact.Mask, phonesInText, "*********67"},
+		{redact.FieldPhonesInText, redact.Hash, phonesInText, hash("+79991234567")},
+
+		{redact.FieldPhrases, redact.Keep, phrases, "позвоните 8 999 123 45 67"},
+		{redact.FieldPhrases, redact.Drop, phrases, ""},
+		{redact.FieldPhrases, redact.Mask, phrases, "позвоните [phone]"},
+		{redact.FieldPhrases, redact.Hash, phrases, "позвоните [phone:" + hash("+79991234567") + "]"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(fmt.Sprintf("%s/%d", tt.field, tt.action), func(t *testing.T) {
+			t.Parallel()
+
+			r := redact.New([]byte(testKey), map[redact.Field]redact.Action{tt.field: tt.action})
+
+			call, lead := testCall(), testLead(t)
+			gotCall, gotLead := r.Call(call), r.Lead(lead)
+
+			if got := tt.get(gotCall, gotLead); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+
+			if original := testCall(); original.CallerNumber != call.CallerNumber || (*call.Phrases)[0].Message != (*original.Phrases)[0].Message {
+				t.Error("original call was modified")
+			}
+
+			if lead.Client.Phones[0].PhoneNumber != "8 999 123 45 67" {
+				t.Error("original lead was modified")
+			}
+		})
+	}
+}
+
+func TestRedactorText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		action redact.Action
+		in     string
+		names  []string
+		want   string
+	}{
+		{
+			name:   "email and phone",
+			action: redact.Hash,
+			in:     "почта user12@mail.ru, телефон +7 999 123-45-67",
+			want:   "почта [email:" + hash("user12@mail.ru") + "], телефон [phone:" + hash("+79991234567") + "]",
+		},
+		{
+			name:   "email and phone masked",
+			action: redact.Mask,
+			in:     "User@Mail.ru или 89991234567",
+			want:   "[email] или [phone]",
+		},
+		{
+			name:   "card passes luhn",
+			action: redact.Hash,
+			in:     "карта 4111 1111 1111 1111",
+			want:   "карта [card:" + hash("4111111111111111") + "]",
+		},
+		{
+			name:   "short number is kept",
+			action: redact.Mask,
+			in:     "код 123-45-67",
+			want:   "код 123-45-67",
+		},
+		{
+			name:   "introduced name",
+			action: redact.Mask,
+			in:     "Меня зовут Анна, спасибо",
+			want:   "Меня зовут [name], спасибо",
+		},
+		{
+			name:   "cyrillic names",
+			action: redact.Hash,
+			in:     "Петров звонил, Петровский нет",
+			names:  []string{"Петров"},
+			want:   "[name:" + hash("петров") + "] звонил, Петровский нет",
+		},
+		{
+			name:   "keep",
+			action: redact.Keep,
+			in:     "user@mail.ru",
+			want:   "user@mail.ru",
+		},
+		{
+			name:   "drop",
+			action: redact.Drop,
+			in:     "user@mail.ru",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := redact.New([]byte(testKey), map[redact.Field]redact.Action{redact.FieldPhrases: tt.action})
+
+			if got := r.Text(tt.in, tt.names...); got != tt.want {
+				t.Errorf("Text(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorTextHashesAreNotRedactedAgain(t *testing.T) {
+	t.Parallel()
+
+	r := redact.New([]byte(testKey), map[redact.Field]redact.Action{redact.FieldPhrases: redact.Hash})
+	token := regexp.MustCompile(`^\[email:[0-9a-f]{32}\] \[phone:[0-9a-f]{32}\]$`)
+
+	for i := 0; i < 2000; i++ {
+		got := r.Text(fmt.Sprintf("user%d@mail.ru 8999%07d", i, i))
+		if !token.MatchString(got) {
+			t.Fatalf("Text() = %q, want email and phone tokens", got)
+		}
+	}
+}
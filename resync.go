@@ -0,0 +1,123 @@
+package calltouch
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ChangeReason string
+
+const (
+	ChangeNew       ChangeReason = "new"       // Звонок ранее не передавался.
+	ChangeCallphase ChangeReason = "callphase" // Изменилась фаза звонка.
+	ChangeTags      ChangeReason = "tags"      // Изменились теги звонка.
+	ChangeOrders    ChangeReason = "orders"    // Изменились связанные сделки.
+	ChangeManager   ChangeReason = "manager"   // Назначен или изменен менеджер.
+	ChangeOther     ChangeReason = "other"     // Изменились прочие поля.
+)
+
+// CallChange - звонок, изменившийся с момента последней передачи, и причины изменения.
+type CallChange struct {
+	Call    Call
+	Reasons []ChangeReason
+}
+
+// Resyncer повторно выгружает звонки за скользящее окно и возвращает только изменившиеся с прошлой передачи.
+type Resyncer struct {
+	client *Client
+	store  SnapshotStore
+}
+
+func NewResyncer(client *Client, store SnapshotStore) *Resyncer {
+	return &Resyncer{
+		client: client,
+		store:  store,
+	}
+}
+
+// ResyncCalls выгружает звонки за последние window, сравнивает их по CallID с ранее переданными
+// и передает в deliver новые и измененные звонки. Снимки сохраняются, только если deliver вернул nil:
+// при ошибке доставки те же изменения будут возвращены при следующем вызове. Если изменений нет,
+// deliver не вызывается. Снимки старше окна удаляются из хранилища.
+func (r *Resyncer) ResyncCalls(ctx context.Context, siteID int, window time.Duration, options map[string]bool, deliver func(ctx context.Context, changes []CallChange) error) error {
+	now := time.Now()
+	period := Period{DateFrom: startOfDay(now.Add(-window)), DateTo: now}
+
+	previous, err := r.store.LoadCalls(ctx, siteID)
+	if err != nil {
+		return err
+	}
+
+	calls, err := r.client.CallsDiary(ctx, siteID, period, options)
+	if err != nil {
+		return err
+	}
+
+	changes := make([]CallChange, 0)
+	snapshots := make(map[int]Call, len(calls))
+
+	for id, call := range previous {
+		date, dErr := call.Time()
+		if dErr == nil && !date.Before(period.DateFrom) {
+			snapshots[id] = call
+		}
+	}
+
+	for _, call := range calls {
+		snapshots[call.CallID] = call
+
+		old, ok := previous[call.CallID]
+		if !ok {
+			changes = append(changes, CallChange{Call: call, Reasons: []ChangeReason{ChangeNew}})
+
+			continue
+		}
+
+		reasons := callChangeReasons(old, call)
+		if len(reasons) > 0 {
+			changes = append(changes, CallChange{Call: call, Reasons: reasons})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Call.CallID < changes[j].Call.CallID })
+
+	if len(changes) > 0 {
+		err = deliver(ctx, changes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.store.SaveCalls(ctx, siteID, snapshots)
+}
+
+func callChangeReasons(old, cur Call) []ChangeReason {
+	reasons := make([]ChangeReason, 0)
+	seen := make(map[ChangeReason]bool)
+
+	for _, change := range DiffCalls(old, cur) {
+		field, _, _ := strings.Cut(change.Path, ".")
+		field, _, _ = strings.Cut(field, "[")
+
+		reason := ChangeOther
+		switch field {
+		case "callphase":
+			reason = ChangeCallphase
+		case "callTags":
+			reason = ChangeTags
+		case "orders":
+			reason = ChangeOrders
+		case "manager":
+			reason = ChangeManager
+		}
+
+		if !seen[reason] {
+			seen[reason] = true
+			reasons = append(reasons, reason)
+		}
+	}
+
+	return reasons
+}
@@ -0,0 +1,59 @@
+package calltouch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+type Site struct {
+	SiteID   int    `json:"siteId"`   // Идентификатор сайта в Calltouch.
+	SiteName string `json:"siteName"` // Название сайта.
+	URL      string `json:"url"`      // Адрес отслеживаемого сайта.
+}
+
+// Ping проверяет, что токен действителен, и возвращает список доступных по нему сайтов.
+// Если токен отозван или неверен, возвращаемая ошибка удовлетворяет errors.Is(err, ErrUnauthorized).
+func (c *Client) Ping(ctx context.Context) ([]Site, error) {
+	u := c.sitesURLBuilder()
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+
+	resp, respErr := http.DefaultClient.Do(req)
+	if respErr != nil {
+		return nil, respErr
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var sites []Site
+
+	err := json.NewDecoder(resp.Body).Decode(&sites)
+	if err != nil {
+		return nil, err
+	}
+
+	return sites, nil
+}
+
+func (c *Client) sitesURLBuilder() url.URL {
+	u := url.URL{
+		Scheme: "https",
+		Host:   "api.calltouch.ru",
+		Path:   "calls-service/RestAPI/sites",
+	}
+
+	params := url.Values{}
+	params.Add("clientApiId", c.accessToken)
+	u.RawQuery = params.Encode()
+
+	return u
+}
@@ -0,0 +1,115 @@
+package calltouch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// SnapshotStore хранит последние переданные потребителю версии звонков по CallID.
+type SnapshotStore interface {
+	LoadCalls(ctx context.Context, siteID int) (map[int]Call, error)
+	SaveCalls(ctx context.Context, siteID int, calls map[int]Call) error
+}
+
+// MemorySnapshotStore хранит снимки звонков в памяти процесса.
+type MemorySnapshotStore struct {
+	mu    sync.Mutex
+	calls map[int]map[int]Call
+}
+
+func NewMemorySnapshotStore() *MemorySnapshotStore {
+	return &MemorySnapshotStore{
+		calls: make(map[int]map[int]Call),
+	}
+}
+
+func (s *MemorySnapshotStore) LoadCalls(_ context.Context, siteID int) (map[int]Call, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	calls := make(map[int]Call, len(s.calls[siteID]))
+	for id, call := range s.calls[siteID] {
+		calls[id] = call
+	}
+
+	return calls, nil
+}
+
+func (s *MemorySnapshotStore) SaveCalls(_ context.Context, siteID int, calls map[int]Call) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := make(map[int]Call, len(calls))
+	for id, call := range calls {
+		saved[id] = call
+	}
+	s.calls[siteID] = saved
+
+	return nil
+}
+
+// FileSnapshotStore хранит снимки звонков в JSON-файле.
+type FileSnapshotStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSnapshotStore(path string) *FileSnapshotStore {
+	return &FileSnapshotStore{
+		path: path,
+	}
+}
+
+func (s *FileSnapshotStore) LoadCalls(_ context.Context, siteID int) (map[int]Call, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshots, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	calls := snapshots[strconv.Itoa(siteID)]
+	if calls == nil {
+		calls = make(map[int]Call)
+	}
+
+	return calls, nil
+}
+
+func (s *FileSnapshotStore) SaveCalls(_ context.Context, siteID int, calls map[int]Call) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshots, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	snapshots[strconv.Itoa(siteID)] = calls
+
+	return writeFileAtomic(s.path, snapshots)
+}
+
+func (s *FileSnapshotStore) read() (map[string]map[int]Call, error) {
+	snapshots := make(map[string]map[int]Call)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshots, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &snapshots)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,123 @@
+package calltouch
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Syncer выгружает из журналов звонков и заявок только новые записи с момента последнего чекпоинта.
+// Чекпоинт сдвигается только после успешной обработки записей, поэтому доставка - не менее одного раза:
+// если обработчик вернул ошибку или процесс упал, те же записи будут выгружены снова.
+type Syncer struct {
+	client *Client
+	store  CheckpointStore
+	start  time.Time
+}
+
+// NewSyncer создает Syncer. start - дата, с которой начинается первая синхронизация, если чекпоинта еще нет.
+func NewSyncer(client *Client, store CheckpointStore, start time.Time) *Syncer {
+	return &Syncer{
+		client: client,
+		store:  store,
+		start:  start,
+	}
+}
+
+// SyncCalls передает в handle звонки, появившиеся после чекпоинта, в порядке возрастания CallID,
+// и сдвигает чекпоинт, если handle вернул nil. Если новых звонков нет, handle не вызывается.
+func (s *Syncer) SyncCalls(ctx context.Context, siteID int, options map[string]bool, handle func(ctx context.Context, calls []Call) error) error {
+	cp, period, err := s.period(ctx, siteID, EndpointCalls)
+	if err != nil {
+		return err
+	}
+
+	calls, err := s.client.CallsDiary(ctx, siteID, period, options)
+	if err != nil {
+		return err
+	}
+
+	fresh := make([]Call, 0, len(calls))
+	for _, call := range calls {
+		if call.CallID > cp.LastID {
+			fresh = append(fresh, call)
+		}
+	}
+
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	sort.Slice(fresh, func(i, j int) bool { return fresh[i].CallID < fresh[j].CallID })
+
+	err = handle(ctx, fresh)
+	if err != nil {
+		return err
+	}
+
+	last := fresh[len(fresh)-1]
+	next := Checkpoint{Date: cp.Date, LastID: last.CallID}
+
+	date, dErr := last.Time()
+	if dErr == nil {
+		next.Date = date
+	}
+
+	return s.store.Save(ctx, siteID, EndpointCalls, next)
+}
+
+// SyncLeads передает в handle заявки, появившиеся после чекпоинта, в порядке возрастания RequestID,
+// и сдвигает чекпоинт, если handle вернул nil. Если новых заявок нет, handle не вызывается.
+// Журнал заявок общий для всех сайтов токена, поэтому чекпоинт заявок один на аккаунт и хранится с siteID 0.
+func (s *Syncer) SyncLeads(ctx context.Context, options map[string]bool, handle func(ctx context.Context, leads []Lead) error) error {
+	cp, period, err := s.period(ctx, 0, EndpointLeads)
+	if err != nil {
+		return err
+	}
+
+	leads, err := s.client.LeadsDiary(ctx, period, options)
+	if err != nil {
+		return err
+	}
+
+	fresh := make([]Lead, 0, len(leads))
+	for _, lead := range leads {
+		if lead.RequestID > cp.LastID {
+			fresh = append(fresh, lead)
+		}
+	}
+
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	sort.Slice(fresh, func(i, j int) bool { return fresh[i].RequestID < fresh[j].RequestID })
+
+	err = handle(ctx, fresh)
+	if err != nil {
+		return err
+	}
+
+	last := fresh[len(fresh)-1]
+
+	return s.store.Save(ctx, 0, EndpointLeads, Checkpoint{Date: last.Time(), LastID: last.RequestID})
+}
+
+func (s *Syncer) period(ctx context.Context, siteID int, endpoint string) (Checkpoint, Period, error) {
+	cp, ok, err := s.store.Load(ctx, siteID, endpoint)
+	if err != nil {
+		return Checkpoint{}, Period{}, err
+	}
+
+	from := s.start
+	if ok && !cp.Date.IsZero() {
+		from = cp.Date
+	}
+
+	now := time.Now()
+	if from.After(now) {
+		from = now
+	}
+
+	return cp, Period{DateFrom: startOfDay(from), DateTo: now}, nil
+}
@@ -0,0 +1,143 @@
+package calltouch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// TagNames возвращает имена всех тегов звонка.
+func (c Call) TagNames() []string {
+	if c.CallTags == nil {
+		return nil
+	}
+
+	names := make([]string, 0)
+	for _, tag := range *c.CallTags {
+		names = append(names, tag.Names...)
+	}
+
+	return names
+}
+
+// TagNames возвращает имена всех тегов заявки.
+func (l Lead) TagNames() []string {
+	if l.RequestTags == nil {
+		return nil
+	}
+
+	names := make([]string, 0)
+	for _, tag := range *l.RequestTags {
+		names = append(names, tag.Names...)
+	}
+
+	return names
+}
+
+type callTagsBody struct {
+	Names []string `json:"names"`
+}
+
+type leadTagName struct {
+	Tag string `json:"tag"`
+}
+
+type leadTagsItem struct {
+	RequestID int           `json:"requestId"`
+	Tags      []leadTagName `json:"tags"`
+}
+
+type leadTagsBody struct {
+	Requests []leadTagsItem `json:"requests"`
+}
+
+// AddCallTags добавляет звонку теги с указанными именами. Несуществующие теги создаются в категории ручных тегов.
+func (c *Client) AddCallTags(ctx context.Context, siteID int, callID int, names ...string) error {
+	return c.changeCallTags(ctx, http.MethodPost, siteID, callID, names)
+}
+
+// RemoveCallTags снимает со звонка теги с указанными именами.
+func (c *Client) RemoveCallTags(ctx context.Context, siteID int, callID int, names ...string) error {
+	return c.changeCallTags(ctx, http.MethodDelete, siteID, callID, names)
+}
+
+// AddLeadTags добавляет заявке теги с указанными именами.
+func (c *Client) AddLeadTags(ctx context.Context, siteID int, requestID int, names ...string) error {
+	return c.changeLeadTags(ctx, "request/tag/add", siteID, requestID, names)
+}
+
+// RemoveLeadTags снимает с заявки теги с указанными именами.
+func (c *Client) RemoveLeadTags(ctx context.Context, siteID int, requestID int, names ...string) error {
+	return c.changeLeadTags(ctx, "request/tag/delete", siteID, requestID, names)
+}
+
+// ListTags возвращает словарь тегов сайта, сгруппированный по категории и типу.
+// Пустые category и tagType не ограничивают выборку.
+func (c *Client) ListTags(ctx context.Context, siteID int, category, tagType string) ([]Tag, error) {
+	var tags []Tag
+
+	err := c.doJSON(ctx, http.MethodGet, c.callsServiceURL(fmt.Sprintf("%v/tags", siteID)), siteID, nil, &tags)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]Tag, 0, len(tags))
+	for _, tag := range tags {
+		if (category == "" || tag.Category == category) && (tagType == "" || tag.Type == tagType) {
+			filtered = append(filtered, tag)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (c *Client) changeCallTags(ctx context.Context, method string, siteID int, callID int, names []string) error {
+	if len(names) == 0 {
+		return &ValidationError{Field: "names", Reason: "at least one tag is required"}
+	}
+
+	u := c.callsServiceURL(fmt.Sprintf("%v/calls/%v/tags", siteID, callID))
+
+	err := c.doJSON(ctx, method, u, siteID, callTagsBody{Names: names}, nil)
+	if err != nil {
+		return asValidationError(err)
+	}
+
+	return nil
+}
+
+func (c *Client) changeLeadTags(ctx context.Context, method string, siteID int, requestID int, names []string) error {
+	if len(names) == 0 {
+		return &ValidationError{Field: "names", Reason: "at least one tag is required"}
+	}
+
+	tags := make([]leadTagName, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, leadTagName{Tag: name})
+	}
+
+	body := leadTagsBody{Requests: []leadTagsItem{{RequestID: requestID, Tags: tags}}}
+
+	err := c.doJSON(ctx, http.MethodPost, leadServiceURL(method), siteID, body, nil)
+	if err != nil {
+		return asValidationError(err)
+	}
+
+	return nil
+}
+
+// callsServiceURL возвращает адрес метода calls-service API с токеном в параметре clientApiId.
+func (c *Client) callsServiceURL(method string) url.URL {
+	u := url.URL{
+		Scheme: "https",
+		Host:   "api.calltouch.ru",
+		Path:   "calls-service/RestAPI/" + method,
+	}
+
+	params := url.Values{}
+	params.Add("clientApiId", c.accessToken)
+	u.RawQuery = params.Encode()
+
+	return u
+}
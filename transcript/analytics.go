@@ -0,0 +1,208 @@
+package transcript
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+)
+
+// Spotter ищет в репликах ключевые слова или фразы, например приветствие по скрипту или упоминания конкурентов.
+type Spotter struct {
+	Name    string   `json:"name"`
+	Speaker *Speaker `json:"speaker,omitempty"` // Только реплики этого участника; nil - любые.
+	Phrases []string `json:"phrases"`           // Подстроки без учета регистра.
+}
+
+// AnalyzeOptions настраивает расчет метрик.
+type AnalyzeOptions struct {
+	// WordsPerSecond - темп речи для оценки времени говорения по числу слов. По умолчанию 2.5.
+	WordsPerSecond float64
+	// MinSilence - минимальная пауза, учитываемая как молчание. По умолчанию 3 секунды.
+	MinSilence time.Duration
+	Spotters   []Spotter
+}
+
+// SpotMatch - срабатывание Spotter в реплике.
+type SpotMatch struct {
+	Spotter string        `json:"spotter"`
+	Phrase  string        `json:"phrase"`
+	Speaker Speaker       `json:"speaker"`
+	At      time.Duration `json:"at"`
+}
+
+// ConversationStats - метрики разговора одного звонка.
+type ConversationStats struct {
+	CallID  int    `json:"callId"`
+	Manager string `json:"manager"`
+
+	Duration             time.Duration `json:"duration"`
+	OperatorTalk         time.Duration `json:"operatorTalk"`         // Оценка времени речи оператора.
+	ClientTalk           time.Duration `json:"clientTalk"`           // Оценка времени речи клиента.
+	TalkRatio            float64       `json:"talkRatio"`            // Доля речи оператора от общей речи, 0..1.
+	Turns                int           `json:"turns"`                // Количество реплик (смен говорящего).
+	LongestMonologue     time.Duration `json:"longestMonologue"`     // Самая длинная реплика.
+	LongestMonologueBy   Speaker       `json:"longestMonologueBy"`   // Кто произнес самую длинную реплику.
+	SilenceGaps          int           `json:"silenceGaps"`          // Количество пауз не короче MinSilence.
+	TotalSilence         time.Duration `json:"totalSilence"`         // Суммарная длительность таких пауз.
+	LongestSilence       time.Duration `json:"longestSilence"`       // Самая длинная пауза.
+	FirstResponseLatency time.Duration `json:"firstResponseLatency"` // От начала разговора до первой реплики оператора, -1 если оператор молчал.
+	Spots                []SpotMatch   `json:"spots"`
+}
+
+// Analyze рассчитывает метрики разговора по Call.Phrases. Время речи оценивается по числу слов
+// и ограничивается интервалом реплики; остаток интервала считается паузой.
+func Analyze(call calltouch.Call, options AnalyzeOptions) (ConversationStats, error) {
+	t, err := New(call)
+	if err != nil {
+		return ConversationStats{}, err
+	}
+
+	stats := t.Analyze(options)
+	stats.Manager = call.Manager
+
+	return stats, nil
+}
+
+// Analyze рассчитывает метрики разговора по расшифровке.
+func (t Transcript) Analyze(options AnalyzeOptions) ConversationStats {
+	if options.WordsPerSecond <= 0 {
+		options.WordsPerSecond = 2.5
+	}
+	if options.MinSilence <= 0 {
+		options.MinSilence = 3 * time.Second
+	}
+
+	stats := ConversationStats{
+		CallID:               t.CallID,
+		Duration:             t.Duration,
+		Turns:                len(t.Turns),
+		FirstResponseLatency: -1,
+		Spots:                make([]SpotMatch, 0),
+	}
+
+	for _, turn := range t.Turns {
+		span := turn.End - turn.Start
+		speech := time.Duration(float64(len(strings.Fields(turn.Text))) / options.WordsPerSecond * float64(time.Second))
+		if speech > span {
+			speech = span
+		}
+
+		switch turn.Speaker {
+		case Operator:
+			stats.OperatorTalk += speech
+			if stats.FirstResponseLatency < 0 {
+				stats.FirstResponseLatency = turn.Start
+			}
+		case Client:
+			stats.ClientTalk += speech
+		}
+
+		if speech > stats.LongestMonologue {
+			stats.LongestMonologue = speech
+			stats.LongestMonologueBy = turn.Speaker
+		}
+
+		if silence := span - speech; silence >= options.MinSilence {
+			stats.SilenceGaps++
+			stats.TotalSilence += silence
+			if silence > stats.LongestSilence {
+				stats.LongestSilence = silence
+			}
+		}
+
+		stats.Spots = append(stats.Spots, spot(turn, options.Spotters)...)
+	}
+
+	if total := stats.OperatorTalk + stats.ClientTalk; total > 0 {
+		stats.TalkRatio = float64(stats.OperatorTalk) / float64(total)
+	}
+
+	return stats
+}
+
+func spot(turn Turn, spotters []Spotter) []SpotMatch {
+	matches := make([]SpotMatch, 0)
+	text := strings.ToLower(turn.Text)
+
+	for _, spotter := range spotters {
+		if spotter.Speaker != nil && *spotter.Speaker != turn.Speaker {
+			continue
+		}
+
+		for _, phrase := range spotter.Phrases {
+			if strings.Contains(text, strings.ToLower(phrase)) {
+				matches = append(matches, SpotMatch{Spotter: spotter.Name, Phrase: phrase, Speaker: turn.Speaker, At: turn.Start})
+			}
+		}
+	}
+
+	return matches
+}
+
+// ManagerStats - метрики разговоров, агрегированные по менеджеру.
+type ManagerStats struct {
+	Manager                 string         `json:"manager"`
+	Calls                   int            `json:"calls"`
+	AvgTalkRatio            float64        `json:"avgTalkRatio"` // Среди звонков, где кто-то говорил.
+	AvgTurns                float64        `json:"avgTurns"`
+	AvgFirstResponseLatency time.Duration  `json:"avgFirstResponseLatency"` // Среди звонков, где оператор говорил.
+	TotalSilence            time.Duration  `json:"totalSilence"`
+	SpotCalls               map[string]int `json:"spotCalls"` // Количество звонков со срабатыванием каждого Spotter.
+}
+
+// AggregateByManager группирует метрики звонков по Manager. Результат отсортирован по имени менеджера.
+func AggregateByManager(stats []ConversationStats) []ManagerStats {
+	byManager := make(map[string]*ManagerStats)
+	talked := make(map[string]int)
+	responded := make(map[string]int)
+
+	for _, s := range stats {
+		m, ok := byManager[s.Manager]
+		if !ok {
+			m = &ManagerStats{Manager: s.Manager, SpotCalls: make(map[string]int)}
+			byManager[s.Manager] = m
+		}
+
+		m.Calls++
+		m.AvgTurns += float64(s.Turns)
+		m.TotalSilence += s.TotalSilence
+
+		// Звонки без речи (пропущенные или выгруженные без withText) не тянут долю речи к нулю.
+		if s.OperatorTalk+s.ClientTalk > 0 {
+			m.AvgTalkRatio += s.TalkRatio
+			talked[s.Manager]++
+		}
+
+		if s.FirstResponseLatency >= 0 {
+			m.AvgFirstResponseLatency += s.FirstResponseLatency
+			responded[s.Manager]++
+		}
+
+		seen := make(map[string]bool)
+		for _, match := range s.Spots {
+			if !seen[match.Spotter] {
+				seen[match.Spotter] = true
+				m.SpotCalls[match.Spotter]++
+			}
+		}
+	}
+
+	result := make([]ManagerStats, 0, len(byManager))
+	for name, m := range byManager {
+		m.AvgTurns /= float64(m.Calls)
+		if n := talked[name]; n > 0 {
+			m.AvgTalkRatio /= float64(n)
+		}
+		if n := responded[name]; n > 0 {
+			m.AvgFirstResponseLatency /= time.Duration(n)
+		}
+
+		result = append(result, *m)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Manager < result[j].Manager })
+
+	return result
+}
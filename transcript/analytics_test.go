@@ -0,0 +1,125 @@
+package transcript_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+	"github.com/mg-realcom/calltouch-sdk/transcript"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	phrases := []calltouch.Phrase{
+		{Channel: 1, Time: "00:00", Message: "добрый день"},
+		{Channel: 0, Time: "00:02", Message: "здравствуйте"},
+		{Channel: 0, Time: "00:03", Message: "хочу заказать"},
+		{Channel: 1, Time: "00:10", Message: "у конкурента дороже"},
+	}
+	call := calltouch.Call{CallID: 7, Manager: "Анна", Duration: 20, Phrases: &phrases}
+
+	got, err := transcript.Analyze(call, transcript.AnalyzeOptions{
+		WordsPerSecond: 1,
+		Spotters:       []transcript.Spotter{{Name: "competitor", Phrases: []string{"КОНКУРЕНТ"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := transcript.ConversationStats{
+		CallID:               7,
+		Manager:              "Анна",
+		Duration:             20 * time.Second,
+		OperatorTalk:         5 * time.Second,
+		ClientTalk:           3 * time.Second,
+		TalkRatio:            5.0 / 8.0,
+		Turns:                3,
+		LongestMonologue:     3 * time.Second,
+		LongestMonologueBy:   transcript.Client,
+		SilenceGaps:          2,
+		TotalSilence:         12 * time.Second,
+		LongestSilence:       7 * time.Second,
+		FirstResponseLatency: 0,
+		Spots: []transcript.SpotMatch{
+			{Spotter: "competitor", Phrase: "КОНКУРЕНТ", Speaker: transcript.Operator, At: 10 * time.Second},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Analyze() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAnalyzeWithoutPhrases(t *testing.T) {
+	t.Parallel()
+
+	got, err := transcript.Analyze(calltouch.Call{CallID: 1, Duration: 30}, transcript.AnalyzeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Turns != 0 || got.TalkRatio != 0 || got.FirstResponseLatency != -1 || len(got.Spots) != 0 {
+		t.Errorf("Analyze() = %+v, want empty stats with FirstResponseLatency -1", got)
+	}
+}
+
+func TestAnalyzeSpotterSpeaker(t *testing.T) {
+	t.Parallel()
+
+	operator := transcript.Operator
+	phrases := []calltouch.Phrase{
+		{Channel: 0, Time: "00:00", Message: "здравствуйте"},
+		{Channel: 1, Time: "00:02", Message: "Здравствуйте, компания"},
+	}
+
+	got, err := transcript.Analyze(calltouch.Call{Duration: 5, Phrases: &phrases}, transcript.AnalyzeOptions{
+		Spotters: []transcript.Spotter{{Name: "greeting", Speaker: &operator, Phrases: []string{"здравствуйте"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Spots) != 1 || got.Spots[0].Speaker != transcript.Operator || got.Spots[0].At != 2*time.Second {
+		t.Errorf("Spots = %+v, want one operator match at 2s", got.Spots)
+	}
+}
+
+func TestAggregateByManager(t *testing.T) {
+	t.Parallel()
+
+	stats := []transcript.ConversationStats{
+		{
+			Manager: "Анна", OperatorTalk: 6 * time.Second, ClientTalk: 4 * time.Second, TalkRatio: 0.6,
+			Turns: 4, FirstResponseLatency: 2 * time.Second, TotalSilence: 5 * time.Second,
+			Spots: []transcript.SpotMatch{{Spotter: "greeting"}, {Spotter: "greeting"}},
+		},
+		{
+			Manager: "Анна", OperatorTalk: 4 * time.Second, ClientTalk: 6 * time.Second, TalkRatio: 0.4,
+			Turns: 2, FirstResponseLatency: 4 * time.Second,
+			Spots: []transcript.SpotMatch{{Spotter: "greeting"}, {Spotter: "competitor"}},
+		},
+		// Пропущенный звонок без реплик не должен влиять на среднюю долю речи и задержку ответа.
+		{Manager: "Анна", FirstResponseLatency: -1},
+		{Manager: "Борис", FirstResponseLatency: -1},
+	}
+
+	got := transcript.AggregateByManager(stats)
+	want := []transcript.ManagerStats{
+		{
+			Manager:                 "Анна",
+			Calls:                   3,
+			AvgTalkRatio:            0.5,
+			AvgTurns:                2,
+			AvgFirstResponseLatency: 3 * time.Second,
+			TotalSilence:            5 * time.Second,
+			SpotCalls:               map[string]int{"greeting": 2, "competitor": 1},
+		},
+		{Manager: "Борис", Calls: 1, SpotCalls: map[string]int{}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateByManager() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,249 @@
+// Package transcript строит расшифровку разговора из Call.Phrases и выводит ее
+// в виде текста, субтитров SRT и WebVTT или JSON.
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+)
+
+// Speaker - участник разговора. Значения совпадают с Phrase.Channel.
+type Speaker int
+
+const (
+	Client   Speaker = 0
+	Operator Speaker = 1
+)
+
+func (s Speaker) String() string {
+	switch s {
+	case Operator:
+		return "operator"
+	case Client:
+		return "client"
+	default:
+		return "channel_" + strconv.Itoa(int(s))
+	}
+}
+
+func (s Speaker) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON принимает как имя участника ("operator", "client"), так и номер канала.
+func (s *Speaker) UnmarshalJSON(b []byte) error {
+	var channel int
+	if json.Unmarshal(b, &channel) == nil {
+		*s = Speaker(channel)
+
+		return nil
+	}
+
+	var name string
+
+	err := json.Unmarshal(b, &name)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "operator":
+		*s = Operator
+	case "client":
+		*s = Client
+	default:
+		return fmt.Errorf("transcript: unknown speaker %q", name)
+	}
+
+	return nil
+}
+
+// DefaultLabels возвращает подписи участников для текстовых форматов. Каждый вызов создает новую карту.
+func DefaultLabels() map[Speaker]string {
+	return map[Speaker]string{
+		Operator: "Оператор",
+		Client:   "Клиент",
+	}
+}
+
+// Turn - реплика: подряд идущие фразы одного участника.
+type Turn struct {
+	Speaker Speaker       `json:"speaker"`
+	Start   time.Duration `json:"-"`
+	End     time.Duration `json:"-"`
+	Text    string        `json:"text"`
+	Phrases int           `json:"phrases"` // Количество исходных фраз в реплике.
+}
+
+func (t Turn) MarshalJSON() ([]byte, error) {
+	type turn Turn
+
+	return json.Marshal(struct {
+		turn
+		StartMs int64 `json:"startMs"`
+		EndMs   int64 `json:"endMs"`
+	}{turn(t), t.Start.Milliseconds(), t.End.Milliseconds()})
+}
+
+// Transcript - расшифровка разговора.
+type Transcript struct {
+	CallID   int                `json:"callId"`
+	Duration time.Duration      `json:"-"`
+	Turns    []Turn             `json:"turns"`
+	Labels   map[Speaker]string `json:"-"` // Подписи участников, по умолчанию DefaultLabels().
+}
+
+// New строит расшифровку звонка (выгруженного с withText). Подряд идущие фразы одного участника
+// объединяются в одну реплику. Конец реплики - начало следующей, для последней - конец разговора.
+func New(call calltouch.Call) (Transcript, error) {
+	t := Transcript{
+		CallID:   call.CallID,
+		Duration: time.Duration(call.Duration) * time.Second,
+		Turns:    make([]Turn, 0),
+		Labels:   DefaultLabels(),
+	}
+
+	if call.Phrases == nil {
+		return t, nil
+	}
+
+	for i, phrase := range *call.Phrases {
+		start, err := ParseTime(phrase.Time)
+		if err != nil {
+			return Transcript{}, fmt.Errorf("transcript: phrase %d: %w", i, err)
+		}
+
+		text := strings.TrimSpace(phrase.Message)
+		if text == "" {
+			continue
+		}
+
+		speaker := Speaker(phrase.Channel)
+
+		if n := len(t.Turns); n > 0 && t.Turns[n-1].Speaker == speaker {
+			t.Turns[n-1].Text += " " + text
+			t.Turns[n-1].Phrases++
+
+			continue
+		}
+
+		t.Turns = append(t.Turns, Turn{Speaker: speaker, Start: start, Text: text, Phrases: 1})
+	}
+
+	for i := range t.Turns {
+		if i+1 < len(t.Turns) {
+			t.Turns[i].End = t.Turns[i+1].Start
+		} else {
+			t.Turns[i].End = t.Duration
+		}
+
+		if t.Turns[i].End <= t.Turns[i].Start {
+			t.Turns[i].End = t.Turns[i].Start + time.Second
+		}
+	}
+
+	return t, nil
+}
+
+// ParseTime разбирает метку времени фразы в формате ММ:СС или ЧЧ:ММ:СС.
+func ParseTime(s string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid phrase time %q", s)
+	}
+
+	var total time.Duration
+	for _, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid phrase time %q", s)
+		}
+
+		total = total*60 + time.Duration(n*float64(time.Second))
+	}
+
+	return total, nil
+}
+
+func (t Transcript) label(s Speaker) string {
+	labels := t.Labels
+	if labels == nil {
+		labels = DefaultLabels()
+	}
+
+	if label, ok := labels[s]; ok {
+		return label
+	}
+
+	return s.String()
+}
+
+// Text возвращает расшифровку в виде текста: по строке на реплику с меткой времени и подписью участника.
+func (t Transcript) Text() string {
+	var b strings.Builder
+
+	for _, turn := range t.Turns {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", formatClock(turn.Start), t.label(turn.Speaker), turn.Text)
+	}
+
+	return b.String()
+}
+
+// SRT возвращает расшифровку в формате субтитров SubRip.
+func (t Transcript) SRT() string {
+	var b strings.Builder
+
+	for i, turn := range t.Turns {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s: %s\n\n", i+1,
+			formatTimestamp(turn.Start, ','), formatTimestamp(turn.End, ','), t.label(turn.Speaker), turn.Text)
+	}
+
+	return b.String()
+}
+
+// WebVTT возвращает расшифровку в формате WebVTT с голосами участников в тегах <v>.
+func (t Transcript) WebVTT() string {
+	var b strings.Builder
+
+	b.WriteString("WEBVTT\n\n")
+
+	for _, turn := range t.Turns {
+		fmt.Fprintf(&b, "%s --> %s\n<v %s>%s\n\n",
+			formatTimestamp(turn.Start, '.'), formatTimestamp(turn.End, '.'), t.label(turn.Speaker), vttEscape(turn.Text))
+	}
+
+	return b.String()
+}
+
+// JSON возвращает расшифровку в структурированном виде.
+func (t Transcript) JSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Transcript
+		DurationMs int64 `json:"durationMs"`
+	}{t, t.Duration.Milliseconds()})
+}
+
+func formatClock(d time.Duration) string {
+	seconds := int(d / time.Second)
+
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+func formatTimestamp(d time.Duration, sep byte) string {
+	ms := d.Milliseconds()
+
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+func vttEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,187 @@
+package transcript_test
+
+import (
+	"testing"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+	"github.com/mg-realcom/calltouch-sdk/transcript"
+)
+
+func TestParseTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "00:00", want: 0},
+		{in: "01:05", want: 65 * time.Second},
+		{in: " 12:30 ", want: 12*time.Minute + 30*time.Second},
+		{in: "00:01.5", want: 1500 * time.Millisecond},
+		{in: "1:02:03", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{in: "01:00:00.250", want: time.Hour + 250*time.Millisecond},
+		{in: "90:00", want: 90 * time.Minute},
+		{in: "", wantErr: true},
+		{in: "42", wantErr: true},
+		{in: "1:2:3:4", wantErr: true},
+		{in: "aa:10", wantErr: true},
+		{in: "00:-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := transcript.ParseTime(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTime(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseTime(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMergesPhrases(t *testing.T) {
+	t.Parallel()
+
+	phrases := []calltouch.Phrase{
+		{Channel: 1, Time: "00:00", Message: "Добрый день."},
+		{Channel: 1, Time: "00:02", Message: " Слушаю вас. "},
+		{Channel: 0, Time: "00:05", Message: "Здравствуйте"},
+		{Channel: 0, Time: "00:06", Message: "   "},
+		{Channel: 1, Time: "00:09", Message: "Да"},
+		{Channel: 0, Time: "00:09", Message: "Спасибо"},
+	}
+
+	got, err := transcript.New(calltouch.Call{CallID: 3, Duration: 8, Phrases: &phrases})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []transcript.Turn{
+		{Speaker: transcript.Operator, Start: 0, End: 5 * time.Second, Text: "Добрый день. Слушаю вас.", Phrases: 2},
+		{Speaker: transcript.Client, Start: 5 * time.Second, End: 9 * time.Second, Text: "Здравствуйте", Phrases: 1},
+		// Следующая реплика начинается в то же время: длительность не может быть нулевой.
+		{Speaker: transcript.Operator, Start: 9 * time.Second, End: 10 * time.Second, Text: "Да", Phrases: 1},
+		// Метка последней фразы позже конца разговора.
+		{Speaker: transcript.Client, Start: 9 * time.Second, End: 10 * time.Second, Text: "Спасибо", Phrases: 1},
+	}
+
+	if len(got.Turns) != len(want) {
+		t.Fatalf("New() turns = %+v, want %+v", got.Turns, want)
+	}
+
+	for i := range want {
+		if got.Turns[i] != want[i] {
+			t.Errorf("turn %d = %+v, want %+v", i, got.Turns[i], want[i])
+		}
+	}
+}
+
+func TestNewInvalidTime(t *testing.T) {
+	t.Parallel()
+
+	phrases := []calltouch.Phrase{{Channel: 0, Time: "soon", Message: "Алло"}}
+
+	if _, err := transcript.New(calltouch.Call{Phrases: &phrases}); err == nil {
+		t.Error("New() accepted an invalid phrase time")
+	}
+}
+
+func testTranscript() transcript.Transcript {
+	return transcript.Transcript{
+		CallID:   9,
+		Duration: time.Hour + 2*time.Second,
+		Turns: []transcript.Turn{
+			{Speaker: transcript.Operator, Start: 1500 * time.Millisecond, End: 4 * time.Second, Text: "Цена <100> & доставка", Phrases: 1},
+			{Speaker: transcript.Client, Start: time.Hour + 500*time.Millisecond, End: time.Hour + 2*time.Second, Text: "Хорошо", Phrases: 2},
+		},
+		Labels: transcript.DefaultLabels(),
+	}
+}
+
+func TestFormats(t *testing.T) {
+	t.Parallel()
+
+	tr := testTranscript()
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "text",
+			got:  tr.Text(),
+			want: "[00:01] Оператор: Цена <100> & доставка\n" +
+				"[1:00:00] Клиент: Хорошо\n",
+		},
+		{
+			name: "srt",
+			got:  tr.SRT(),
+			want: "1\n00:00:01,500 --> 00:00:04,000\nОператор: Цена <100> & доставка\n\n" +
+				"2\n01:00:00,500 --> 01:00:02,000\nКлиент: Хорошо\n\n",
+		},
+		{
+			name: "webvtt",
+			got:  tr.WebVTT(),
+			want: "WEBVTT\n\n" +
+				"00:00:01.500 --> 00:00:04.000\n<v Оператор>Цена &lt;100&gt; &amp; доставка\n\n" +
+				"01:00:00.500 --> 01:00:02.000\n<v Клиент>Хорошо\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	got, err := testTranscript().JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"callId":9,"turns":[` +
+		`{"speaker":"operator","text":"Цена \u003c100\u003e \u0026 доставка","phrases":1,"startMs":1500,"endMs":4000},` +
+		`{"speaker":"client","text":"Хорошо","phrases":2,"startMs":3600500,"endMs":3602000}` +
+		`],"durationMs":3602000}`
+
+	if string(got) != want {
+		t.Errorf("JSON() = %s, want %s", got, want)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	t.Parallel()
+
+	tr := testTranscript()
+	tr.Labels[transcript.Operator] = "Менеджер"
+	delete(tr.Labels, transcript.Client)
+
+	want := "[00:01] Менеджер: Цена <100> & доставка\n[1:00:00] client: Хорошо\n"
+	if got := tr.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+
+	if got := transcript.DefaultLabels()[transcript.Operator]; got != "Оператор" {
+		t.Errorf("DefaultLabels() changed by caller: operator = %q", got)
+	}
+}
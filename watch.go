@@ -0,0 +1,107 @@
+package calltouch
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+)
+
+type CallEventType string
+
+const (
+	CallEventNew     CallEventType = "new"     // Новый звонок.
+	CallEventUpdated CallEventType = "updated" // Ранее переданный звонок изменился.
+	CallEventError   CallEventType = "error"   // Ошибка опроса, поле Err заполнено.
+)
+
+type CallEvent struct {
+	Type CallEventType
+	Call Call
+	Err  error
+}
+
+// WatchCalls опрашивает журнал звонков с интервалом interval за скользящее окно в сутки и отправляет в канал
+// новые и изменившиеся звонки. Звонки, найденные при первом опросе, считаются уже известными и не отправляются.
+// Канал не буферизован: пока получатель не прочитал событие, следующий опрос не начинается.
+// Ошибки опроса передаются событиями CallEventError, опрос при этом продолжается.
+// Канал закрывается после отмены ctx.
+func (c *Client) WatchCalls(ctx context.Context, siteID int, interval time.Duration, options map[string]bool) <-chan CallEvent {
+	events := make(chan CallEvent)
+
+	go func() {
+		defer close(events)
+
+		seen := make(map[int]Call)
+		baseline := true
+
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			now := time.Now()
+			period := Period{DateFrom: startOfDay(now.Add(-24 * time.Hour)), DateTo: now}
+
+			calls, err := c.CallsDiary(ctx, siteID, period, options)
+			if err != nil {
+				if ctx.Err() != nil || !sendCallEvent(ctx, events, CallEvent{Type: CallEventError, Err: err}) {
+					return
+				}
+
+				timer.Reset(interval)
+
+				continue
+			}
+
+			sort.Slice(calls, func(i, j int) bool { return calls[i].CallID < calls[j].CallID })
+
+			// Известными остаются только звонки последнего опроса: выпавшие из окна, в том числе
+			// с нераспознанной датой, удаляются.
+			polled := make(map[int]Call, len(calls))
+
+			for _, call := range calls {
+				old, ok := seen[call.CallID]
+				polled[call.CallID] = call
+
+				if baseline {
+					continue
+				}
+
+				event := CallEvent{Type: CallEventNew, Call: call}
+				if ok {
+					if reflect.DeepEqual(old, call) {
+						continue
+					}
+
+					event.Type = CallEventUpdated
+				}
+
+				if !sendCallEvent(ctx, events, event) {
+					return
+				}
+			}
+
+			seen = polled
+			baseline = false
+
+			timer.Reset(interval)
+		}
+	}()
+
+	return events
+}
+
+func sendCallEvent(ctx context.Context, events chan<- CallEvent, event CallEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,235 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+)
+
+// callAlias возвращает JSON-имя поля Call для имени параметра HTTP-уведомления Calltouch в нижнем регистре.
+func callAlias(key string) (string, bool) {
+	switch key {
+	case "callerphone":
+		return "callerNumber", true
+	case "phonenumber":
+		return "phoneNumber", true
+	case "redirectnumber":
+		return "redirectNumber", true
+	case "waiting_connect", "waiting_time":
+		return "waitingConnect", true
+	case "utm_source":
+		return "utmSource", true
+	case "utm_medium":
+		return "utmMedium", true
+	case "utm_campaign":
+		return "utmCampaign", true
+	case "utm_content":
+		return "utmContent", true
+	case "utm_term":
+		return "utmTerm", true
+	case "callurl":
+		return "callUrl", true
+	case "ctcallerid":
+		return "ctCallerId", true
+	case "sessionid":
+		return "sessionId", true
+	case "useragent":
+		return "userAgent", true
+	case "yaclientid":
+		return "yaClientId", true
+	case "clientid":
+		return "clientId", true
+	case "ctclientid":
+		return "ctClientId", true
+	case "ctglobalid":
+		return "ctGlobalId", true
+	case "subpoolname":
+		return "subPoolName", true
+	case "statusdetails":
+		return "statusDetails", true
+	case "callreferenceid":
+		return "callReferenceId", true
+	case "sipcallid":
+		return "sipCallId", true
+	default:
+		return "", false
+	}
+}
+
+// leadAlias возвращает JSON-имя поля Lead для имени параметра HTTP-уведомления Calltouch в нижнем регистре.
+func leadAlias(key string) (string, bool) {
+	switch key {
+	case "requestid":
+		return "requestId", true
+	case "requestnumber":
+		return "requestNumber", true
+	case "ctclientid":
+		return "ctClientId", true
+	case "ctglobalid":
+		return "ctGlobalId", true
+	default:
+		return "", false
+	}
+}
+
+func decodeCall(values url.Values) (calltouch.Call, error) {
+	var call calltouch.Call
+
+	err := decodeValues(values, callAlias, &call)
+	if err != nil {
+		return calltouch.Call{}, err
+	}
+
+	if call.CallID <= 0 {
+		return calltouch.Call{}, ErrMissingCallID
+	}
+
+	if call.Date == "" {
+		ts, tErr := strconv.ParseInt(values.Get("timestamp"), 10, 64)
+		if tErr == nil {
+			call.Date = time.Unix(ts, 0).Format(calltouch.CallDateTimeFormat)
+		}
+	}
+
+	return call, nil
+}
+
+func decodeLead(values url.Values) (calltouch.Lead, error) {
+	var lead calltouch.Lead
+
+	err := decodeValues(values, leadAlias, &lead)
+	if err != nil {
+		return calltouch.Lead{}, err
+	}
+
+	if lead.RequestID <= 0 {
+		return calltouch.Lead{}, ErrMissingLeadID
+	}
+
+	if lead.Date == 0 {
+		ts, tErr := strconv.ParseInt(values.Get("timestamp"), 10, 64)
+		if tErr == nil {
+			lead.Date = ts * 1000
+		}
+	}
+
+	if fio := values.Get("fio"); fio != "" && lead.Client.Fio == "" {
+		lead.Client.Fio = fio
+	}
+
+	for _, key := range []string{"phoneNumber", "phone"} {
+		if phone := values.Get(key); phone != "" && len(lead.Client.Phones) == 0 {
+			lead.Client.Phones = append(lead.Client.Phones, struct {
+				PhoneNumber string `json:"phoneNumber"`
+				PhoneType   string `json:"phoneType"`
+			}{PhoneNumber: phone})
+		}
+	}
+
+	if email := values.Get("email"); email != "" && len(lead.Client.Contacts) == 0 {
+		lead.Client.Contacts = append(lead.Client.Contacts, struct {
+			ContactType  string `json:"contactType"`
+			ContactValue string `json:"contactValue"`
+		}{ContactType: "EMAIL", ContactValue: email})
+	}
+
+	if sessionID, sErr := strconv.Atoi(values.Get("sessionId")); sErr == nil && lead.Session.SessionID == 0 {
+		lead.Session.SessionID = sessionID
+	}
+
+	return lead, nil
+}
+
+// decodeValues заполняет поля верхнего уровня структуры v по их JSON-именам, alias переводит в них имена
+// параметров. Вложенные объекты и массивы ожидаются в виде JSON-строк.
+func decodeValues(values url.Values, alias func(key string) (string, bool), v any) error {
+	fields := make(map[string]reflect.Value)
+
+	rv := reflect.ValueOf(v).Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name, _, _ := strings.Cut(rt.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = rv.Field(i)
+		}
+	}
+
+	for key := range values {
+		name := key
+		if aliased, ok := alias(strings.ToLower(key)); ok {
+			name = aliased
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			continue
+		}
+
+		raw := values.Get(key)
+		if raw == "" {
+			continue
+		}
+
+		err := setField(field, raw)
+		if err != nil {
+			return fmt.Errorf("webhook: invalid %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(b)
+	case reflect.Pointer:
+		if field.Type().Elem().Kind() == reflect.Slice || field.Type().Elem().Kind() == reflect.Struct {
+			return json.Unmarshal([]byte(raw), field.Addr().Interface())
+		}
+
+		elem := reflect.New(field.Type().Elem())
+
+		err := setField(elem.Elem(), raw)
+		if err != nil {
+			return err
+		}
+
+		field.Set(elem)
+	case reflect.Interface:
+		var decoded any
+		if json.Unmarshal([]byte(raw), &decoded) != nil {
+			decoded = raw
+		}
+
+		if decoded == nil {
+			return nil
+		}
+
+		field.Set(reflect.ValueOf(decoded))
+	default:
+		return json.Unmarshal([]byte(raw), field.Addr().Interface())
+	}
+
+	return nil
+}
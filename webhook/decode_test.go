@@ -0,0 +1,49 @@
+package webhook_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mg-realcom/calltouch-sdk/webhook"
+)
+
+func TestDecodeAliases(t *testing.T) {
+	t.Parallel()
+
+	var (
+		call webhook.CallEvent
+		lead webhook.LeadEvent
+	)
+
+	h := webhook.NewHandler(1)
+	h.OnCall(func(_ context.Context, event webhook.CallEvent) error {
+		call = event
+
+		return nil
+	})
+	h.OnLead(func(_ context.Context, event webhook.LeadEvent) error {
+		lead = event
+
+		return nil
+	})
+
+	query := "callId=7&CallerPhone=%2B79991234567&utm_source=yandex&waiting_time=12&ctClientId=42&unknown=1"
+	if got := serve(h, "192.0.2.1:5000", query, nil); got != http.StatusOK {
+		t.Fatalf("call status = %d, want %d", got, http.StatusOK)
+	}
+
+	if call.Call.CallID != 7 || call.Call.CallerNumber != "+79991234567" || call.Call.UtmSource != "yandex" ||
+		call.Call.WaitingConnect != 12 || call.Call.CtClientID == nil || *call.Call.CtClientID != 42 {
+		t.Errorf("decoded call = %+v", call.Call)
+	}
+
+	query = "requestId=9&RequestNumber=site-1&ctglobalid=5"
+	if got := serve(h, "192.0.2.1:5000", query, nil); got != http.StatusOK {
+		t.Fatalf("lead status = %d, want %d", got, http.StatusOK)
+	}
+
+	if lead.Lead.RequestID != 9 || lead.Lead.RequestNumber != "site-1" || lead.Lead.CtGlobalID == nil || *lead.Lead.CtGlobalID != 5 {
+		t.Errorf("decoded lead = %+v", lead.Lead)
+	}
+}
@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+)
+
+const (
+	// enrichRequestAllowance - оценка времени одного запроса к API при расчете бюджета обогащения.
+	enrichRequestAllowance = 10 * time.Second
+	// enrichHandlerReserve - время, которое остается обработчикам после обогащения.
+	enrichHandlerReserve = 30 * time.Second
+)
+
+// CallFetcher получает полный звонок по идентификатору. Реализуется *calltouch.Client.
+type CallFetcher interface {
+	Call(ctx context.Context, siteID int, callID int, options map[string]bool) (calltouch.Call, error)
+}
+
+// Enrichment задает, как дополнять звонок из уведомления полными данными из API.
+type Enrichment struct {
+	Fetcher        CallFetcher
+	SiteID         int             // Используется, если siteId не передан в уведомлении.
+	Options        map[string]bool // Флаги выгрузки, например withCallTags, withOrders, withMapVisits.
+	MaxAttempts    int             // Максимум запросов к API. По умолчанию 5.
+	InitialBackoff time.Duration   // Пауза перед первым повтором. По умолчанию 5 секунд.
+	MaxBackoff     time.Duration   // Максимальная пауза между повторами. По умолчанию 1 минута.
+}
+
+// EnrichCalls включает дополнение уведомлений о звонках: перед вызовом обработчиков звонок
+// запрашивается через Fetcher, пока не достигнет финальной фазы или не кончатся попытки.
+// Если попытки исчерпаны, обработчики получают последнюю полученную версию звонка.
+// Если Timeout меньше суммарного времени повторов с запасом на обработчики, он увеличивается.
+func (h *Handler) EnrichCalls(enrichment Enrichment) {
+	if enrichment.MaxAttempts <= 0 {
+		enrichment.MaxAttempts = 5
+	}
+	if enrichment.InitialBackoff <= 0 {
+		enrichment.InitialBackoff = 5 * time.Second
+	}
+	if enrichment.MaxBackoff <= 0 {
+		enrichment.MaxBackoff = time.Minute
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.enrichment = &enrichment
+
+	if budget := enrichment.budget(); h.Timeout < budget {
+		h.Timeout = budget
+	}
+}
+
+// budget возвращает максимальное время обогащения вместе с запасом на обработчики.
+func (e *Enrichment) budget() time.Duration {
+	total := time.Duration(e.MaxAttempts)*enrichRequestAllowance + enrichHandlerReserve
+
+	backoff := e.InitialBackoff
+	for attempt := 1; attempt < e.MaxAttempts; attempt++ {
+		total += backoff
+
+		backoff *= 2
+		if backoff > e.MaxBackoff {
+			backoff = e.MaxBackoff
+		}
+	}
+
+	return total
+}
+
+func (e *Enrichment) enrich(ctx context.Context, event CallEvent) (CallEvent, error) {
+	siteID := event.SiteID
+	if siteID == 0 {
+		siteID = e.SiteID
+	}
+
+	backoff := e.InitialBackoff
+
+	var lastErr error
+	for attempt := 1; attempt <= e.MaxAttempts; attempt++ {
+		call, err := e.Fetcher.Call(ctx, siteID, event.Call.CallID, e.Options)
+		if err == nil {
+			event.Call = call
+			lastErr = nil
+
+			if call.IsFinal() {
+				return event, nil
+			}
+		} else {
+			lastErr = err
+		}
+
+		if attempt == e.MaxAttempts {
+			break
+		}
+
+		// Повтор не начинается, если после него у обработчиков не останется времени.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff+enrichRequestAllowance+enrichHandlerReserve {
+			break
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return event, ctx.Err()
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > e.MaxBackoff {
+			backoff = e.MaxBackoff
+		}
+	}
+
+	if lastErr != nil {
+		return event, fmt.Errorf("webhook: enrich call %d: %w", event.Call.CallID, lastErr)
+	}
+
+	return event, nil
+}
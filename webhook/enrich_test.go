@@ -0,0 +1,159 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+	"github.com/mg-realcom/calltouch-sdk/webhook"
+)
+
+type fakeFetcher struct {
+	calls   int32
+	results []calltouch.Call // Результаты по номеру запроса; после последнего повторяется последний.
+	err     error
+}
+
+func (f *fakeFetcher) Call(_ context.Context, _ int, callID int, _ map[string]bool) (calltouch.Call, error) {
+	n := int(atomic.AddInt32(&f.calls, 1))
+
+	if f.err != nil {
+		return calltouch.Call{}, f.err
+	}
+
+	if n > len(f.results) {
+		n = len(f.results)
+	}
+
+	call := f.results[n-1]
+	call.CallID = callID
+
+	return call, nil
+}
+
+func TestEnrichCalls(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		fetcher   *fakeFetcher
+		attempts  int
+		wantPhase string
+		wantCalls int32
+		wantErr   bool
+	}{
+		{
+			name:      "final on first attempt",
+			fetcher:   &fakeFetcher{results: []calltouch.Call{{Callphase: calltouch.CallphaseCompleted}}},
+			attempts:  3,
+			wantPhase: calltouch.CallphaseCompleted,
+			wantCalls: 1,
+		},
+		{
+			name:      "retried until final",
+			fetcher:   &fakeFetcher{results: []calltouch.Call{{Callphase: "calldisconnected"}, {Callphase: calltouch.CallphaseCompleted}}},
+			attempts:  3,
+			wantPhase: calltouch.CallphaseCompleted,
+			wantCalls: 2,
+		},
+		{
+			name:      "last version after attempts run out",
+			fetcher:   &fakeFetcher{results: []calltouch.Call{{Callphase: "calldisconnected"}}},
+			attempts:  2,
+			wantPhase: "calldisconnected",
+			wantCalls: 2,
+		},
+		{
+			name:      "notification passed through on errors",
+			fetcher:   &fakeFetcher{err: errors.New("unavailable")},
+			attempts:  2,
+			wantPhase: "callconnected",
+			wantCalls: 2,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				got    calltouch.Call
+				errs   int32
+				events int32
+			)
+
+			h := webhook.NewHandler(1)
+			h.ErrorLog = func(error) { atomic.AddInt32(&errs, 1) }
+			h.OnCall(func(_ context.Context, event webhook.CallEvent) error {
+				atomic.AddInt32(&events, 1)
+				got = event.Call
+
+				return nil
+			})
+			h.EnrichCalls(webhook.Enrichment{Fetcher: tt.fetcher, MaxAttempts: tt.attempts, InitialBackoff: time.Millisecond})
+
+			if code := serve(h, "192.0.2.1:5000", "callId=5&callphase=callconnected", nil); code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", code, http.StatusOK)
+			}
+
+			if events != 1 || got.CallID != 5 || got.Callphase != tt.wantPhase {
+				t.Errorf("dispatched %d events, call %d in phase %q; want 1 event, call 5 in phase %q", events, got.CallID, got.Callphase, tt.wantPhase)
+			}
+
+			if n := atomic.LoadInt32(&tt.fetcher.calls); n != tt.wantCalls {
+				t.Errorf("fetched %d times, want %d", n, tt.wantCalls)
+			}
+
+			if (errs > 0) != tt.wantErr {
+				t.Errorf("logged %d errors, want error: %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnrichCallsRaisesTimeout(t *testing.T) {
+	t.Parallel()
+
+	h := webhook.NewHandler(1)
+	h.EnrichCalls(webhook.Enrichment{Fetcher: &fakeFetcher{}, MaxAttempts: 5, InitialBackoff: 5 * time.Second, MaxBackoff: time.Minute})
+
+	// 5 запросов по 10 секунд, паузы 5+10+20+40 секунд и 30 секунд на обработчики.
+	if want := 155 * time.Second; h.Timeout != want {
+		t.Errorf("Timeout = %v, want %v", h.Timeout, want)
+	}
+}
+
+// Запускается с -race: EnrichCalls на работающем обработчике не должен гоняться с чтением Timeout.
+func TestEnrichCallsOnLiveHandler(t *testing.T) {
+	t.Parallel()
+
+	h := webhook.NewHandler(10)
+	h.OnCall(func(context.Context, webhook.CallEvent) error { return nil })
+
+	fetcher := &fakeFetcher{results: []calltouch.Call{{Callphase: calltouch.CallphaseCompleted}}}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 20; i++ {
+			h.EnrichCalls(webhook.Enrichment{Fetcher: fetcher, MaxAttempts: i%3 + 1})
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		serve(h, "192.0.2.1:5000", "callId=1&callphase=callconnected", nil)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,271 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSecretParam  = "secret"
+	DefaultSecretHeader = "X-Calltouch-Secret"
+
+	// DefaultReplayTTL - срок хранения идентификаторов событий, если RejectReplays получил ttl <= 0.
+	DefaultReplayTTL = time.Hour
+)
+
+// AllowNetworks ограничивает адреса отправителей уведомлений списком подсетей в нотации CIDR.
+// Запросы с других адресов получают 403. Без вызова проверка адреса не выполняется.
+func (h *Handler) AllowNetworks(cidrs ...string) error {
+	networks, err := parseCIDRs(cidrs)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.allowed = networks
+
+	return nil
+}
+
+// TrustProxies задает подсети доверенных прокси. Для запросов от них адрес отправителя
+// берется из заголовка X-Forwarded-For: первый справа адрес, не принадлежащий доверенным прокси.
+func (h *Handler) TrustProxies(cidrs ...string) error {
+	networks, err := parseCIDRs(cidrs)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.proxies = networks
+
+	return nil
+}
+
+// RequireSecret включает проверку общего секрета. Секрет ищется в параметре запроса param
+// и в заголовке header; пустые значения заменяются на DefaultSecretParam и DefaultSecretHeader.
+// Запросы без верного секрета получают 401.
+func (h *Handler) RequireSecret(secret, param, header string) {
+	if param == "" {
+		param = DefaultSecretParam
+	}
+	if header == "" {
+		header = DefaultSecretHeader
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.secret = secret
+	h.secretParam = param
+	h.secretHeader = header
+}
+
+// RejectReplays включает защиту от повторов: идентификаторы последних size событий хранятся ttl,
+// повторное уведомление с тем же идентификатором подтверждается (200), но не передается обработчикам.
+// ttl <= 0 заменяется на DefaultReplayTTL.
+// Идентификатор звонка - callId и callphase, заявки - requestId.
+func (h *Handler) RejectReplays(size int, ttl time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.replays = newReplayCache(size, ttl)
+}
+
+func (h *Handler) checkSource(r *http.Request) bool {
+	h.mu.RLock()
+	allowed, proxies := h.allowed, h.proxies
+	h.mu.RUnlock()
+
+	if len(allowed) == 0 {
+		return true
+	}
+
+	ip := clientIP(r, proxies)
+
+	return ip != nil && containsIP(allowed, ip)
+}
+
+func (h *Handler) checkSecret(r *http.Request, values url.Values) bool {
+	h.mu.RLock()
+	secret, param, header := h.secret, h.secretParam, h.secretHeader
+	h.mu.RUnlock()
+
+	if secret == "" {
+		return true
+	}
+
+	got := r.Header.Get(header)
+	if got == "" {
+		got = values.Get(param)
+	}
+
+	return subtle.ConstantTimeCompare([]byte(got), []byte(secret)) == 1
+}
+
+func eventID(values url.Values) string {
+	if values.Has("callId") {
+		return "call:" + values.Get("callId") + ":" + values.Get("callphase")
+	}
+
+	return "lead:" + values.Get("requestId")
+}
+
+func clientIP(r *http.Request, proxies []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !containsIP(proxies, ip) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if hop == nil {
+			return nil
+		}
+
+		ip = hop
+		if !containsIP(proxies, hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if strings.Contains(cidr, ":") {
+				cidr += "/128"
+			} else {
+				cidr += "/32"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+// replayCache - ограниченный по размеру LRU-кэш идентификаторов событий со сроком жизни.
+// Записи связаны в двусвязный список от самой новой (head) к самой старой (tail).
+type replayCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	head  *replayEntry
+	tail  *replayEntry
+	items map[string]*replayEntry
+}
+
+type replayEntry struct {
+	id         string
+	expires    time.Time
+	prev, next *replayEntry
+}
+
+func newReplayCache(size int, ttl time.Duration) *replayCache {
+	if size <= 0 {
+		size = 1
+	}
+	if ttl <= 0 {
+		ttl = DefaultReplayTTL
+	}
+
+	return &replayCache{
+		size:  size,
+		ttl:   ttl,
+		items: make(map[string]*replayEntry),
+	}
+}
+
+// add запоминает идентификатор и возвращает false, если он уже был получен в пределах ttl.
+func (c *replayCache) add(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	if entry, ok := c.items[id]; ok {
+		if now.Before(entry.expires) {
+			return false
+		}
+
+		c.unlink(entry)
+	}
+
+	entry := &replayEntry{id: id, expires: now.Add(c.ttl), next: c.head}
+	if c.head != nil {
+		c.head.prev = entry
+	}
+	c.head = entry
+	if c.tail == nil {
+		c.tail = entry
+	}
+	c.items[id] = entry
+
+	for len(c.items) > c.size {
+		c.unlink(c.tail)
+	}
+
+	return true
+}
+
+// remove забывает идентификатор, если событие не было принято.
+func (c *replayCache) remove(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.items[id]; ok {
+		c.unlink(entry)
+	}
+}
+
+func (c *replayCache) unlink(entry *replayEntry) {
+	if entry.prev != nil {
+		entry.prev.next = entry.next
+	} else {
+		c.head = entry.next
+	}
+
+	if entry.next != nil {
+		entry.next.prev = entry.prev
+	} else {
+		c.tail = entry.prev
+	}
+
+	entry.prev, entry.next = nil, nil
+	delete(c.items, entry.id)
+}
@@ -0,0 +1,275 @@
+package webhook_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mg-realcom/calltouch-sdk/webhook"
+)
+
+func newCallHandler(t *testing.T, calls *int32) *webhook.Handler {
+	t.Helper()
+
+	h := webhook.NewHandler(10)
+	h.OnCall(func(_ context.Context, _ webhook.CallEvent) error {
+		atomic.AddInt32(calls, 1)
+
+		return nil
+	})
+
+	return h
+}
+
+func serve(h *webhook.Handler, remoteAddr, query string, header http.Header) int {
+	r := httptest.NewRequest(http.MethodGet, "/calltouch?"+query, nil)
+	r.RemoteAddr = remoteAddr
+
+	for key, values := range header {
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	h.Wait()
+
+	return w.Code
+}
+
+func TestAllowNetworks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       int
+	}{
+		{name: "allowed network", remoteAddr: "10.1.2.3:5000", want: http.StatusOK},
+		{name: "allowed single address", remoteAddr: "192.0.2.7:5000", want: http.StatusOK},
+		{name: "allowed ipv6", remoteAddr: "[2001:db8::1]:5000", want: http.StatusOK},
+		{name: "denied", remoteAddr: "203.0.113.5:5000", want: http.StatusForbidden},
+		{name: "unparsable address", remoteAddr: "garbage", want: http.StatusForbidden},
+		{name: "forwarded header from untrusted peer is ignored", remoteAddr: "203.0.113.5:5000", forwarded: []string{"10.1.2.3"}, want: http.StatusForbidden},
+		{name: "proxy forwards allowed client", remoteAddr: "172.16.0.1:5000", forwarded: []string{"10.1.2.3"}, want: http.StatusOK},
+		{name: "proxy forwards denied client", remoteAddr: "172.16.0.1:5000", forwarded: []string{"203.0.113.5"}, want: http.StatusForbidden},
+		{name: "spoofed leftmost hop is ignored", remoteAddr: "172.16.0.1:5000", forwarded: []string{"10.1.2.3, 203.0.113.5"}, want: http.StatusForbidden},
+		{name: "proxy chain is walked from the right", remoteAddr: "172.16.0.1:5000", forwarded: []string{"203.0.113.5, 10.1.2.3, 172.16.0.2"}, want: http.StatusOK},
+		{name: "several header lines are joined", remoteAddr: "172.16.0.1:5000", forwarded: []string{"10.1.2.3", "172.16.0.2"}, want: http.StatusOK},
+		{name: "invalid hop", remoteAddr: "172.16.0.1:5000", forwarded: []string{"10.1.2.3, bogus"}, want: http.StatusForbidden},
+		{name: "proxy without header", remoteAddr: "172.16.0.1:5000", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+
+			h := newCallHandler(t, &calls)
+
+			err := h.AllowNetworks("10.0.0.0/8", "192.0.2.7", "2001:db8::/32")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = h.TrustProxies("172.16.0.0/12")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			header := http.Header{"X-Forwarded-For": tt.forwarded}
+
+			got := serve(h, tt.remoteAddr, "callId=1&callphase=callconnected", header)
+			if got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+
+			wantCalls := int32(0)
+			if tt.want == http.StatusOK {
+				wantCalls = 1
+			}
+
+			if n := atomic.LoadInt32(&calls); n != wantCalls {
+				t.Errorf("dispatched %d calls, want %d", n, wantCalls)
+			}
+		})
+	}
+}
+
+func TestAllowNetworksInvalidCIDR(t *testing.T) {
+	t.Parallel()
+
+	h := webhook.NewHandler(1)
+
+	if err := h.AllowNetworks("10.0.0.0/33"); err == nil {
+		t.Error("AllowNetworks() accepted an invalid CIDR")
+	}
+
+	if err := h.TrustProxies("not-an-ip"); err == nil {
+		t.Error("TrustProxies() accepted an invalid address")
+	}
+}
+
+func TestRequireSecret(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		param  string
+		header string
+		query  string
+		sent   http.Header
+		want   int
+	}{
+		{name: "default param", query: "secret=s3cret", want: http.StatusOK},
+		{name: "default header", sent: http.Header{"X-Calltouch-Secret": {"s3cret"}}, want: http.StatusOK},
+		{name: "custom param", param: "token", query: "token=s3cret", want: http.StatusOK},
+		{name: "custom header", header: "X-Token", sent: http.Header{"X-Token": {"s3cret"}}, want: http.StatusOK},
+		{name: "header wins over param", sent: http.Header{"X-Calltouch-Secret": {"wrong"}}, query: "secret=s3cret", want: http.StatusUnauthorized},
+		{name: "wrong secret", query: "secret=s3cre", want: http.StatusUnauthorized},
+		{name: "longer secret", query: "secret=s3cret2", want: http.StatusUnauthorized},
+		{name: "missing secret", want: http.StatusUnauthorized},
+		{name: "default param ignored when custom set", param: "token", query: "secret=s3cret", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+
+			h := newCallHandler(t, &calls)
+			h.RequireSecret("s3cret", tt.param, tt.header)
+
+			query := "callId=1&callphase=callconnected"
+			if tt.query != "" {
+				query += "&" + tt.query
+			}
+
+			got := serve(h, "192.0.2.1:5000", query, tt.sent)
+			if got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRejectReplays(t *testing.T) {
+	t.Parallel()
+
+	const (
+		callA = "callId=1&callphase=callconnected"
+		callB = "callId=2&callphase=callconnected"
+	)
+
+	tests := []struct {
+		name    string
+		size    int
+		ttl     time.Duration
+		sleep   time.Duration
+		queries []string
+		want    int32
+	}{
+		{name: "replay is not dispatched", size: 10, ttl: time.Minute, queries: []string{callA, callA}, want: 1},
+		{name: "distinct events", size: 10, ttl: time.Minute, queries: []string{callA, callB}, want: 2},
+		{name: "new call phase is a new event", size: 10, ttl: time.Minute, queries: []string{callA, "callId=1&callphase=calldisconnected"}, want: 2},
+		{name: "leads are keyed by request id", size: 10, ttl: time.Minute, queries: []string{"requestId=5", "requestId=5", "requestId=6"}, want: 2},
+		{name: "oldest entry is evicted", size: 1, ttl: time.Minute, queries: []string{callA, callB, callA}, want: 3},
+		{name: "recently seen entry is kept", size: 2, ttl: time.Minute, queries: []string{callA, callB, callB, callA}, want: 2},
+		{name: "zero ttl uses default", size: 10, queries: []string{callA, callA}, want: 1},
+		{name: "expired entry is accepted again", size: 10, ttl: time.Millisecond, sleep: 20 * time.Millisecond, queries: []string{callA, callA}, want: 2},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var events int32
+
+			h := newCallHandler(t, &events)
+			h.OnLead(func(_ context.Context, _ webhook.LeadEvent) error {
+				atomic.AddInt32(&events, 1)
+
+				return nil
+			})
+			h.RejectReplays(tt.size, tt.ttl)
+
+			for _, query := range tt.queries {
+				got := serve(h, "192.0.2.1:5000", query, nil)
+				if got != http.StatusOK {
+					t.Fatalf("status = %d, want %d", got, http.StatusOK)
+				}
+
+				time.Sleep(tt.sleep)
+			}
+
+			if n := atomic.LoadInt32(&events); n != tt.want {
+				t.Errorf("dispatched %d events, want %d", n, tt.want)
+			}
+		})
+	}
+}
+
+func TestRejectReplaysForgetsUnacceptedEvents(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+
+	h := webhook.NewHandler(1)
+	h.OnCall(func(_ context.Context, _ webhook.CallEvent) error {
+		<-release
+
+		return nil
+	})
+	h.RejectReplays(10, time.Minute)
+
+	send := func(query string) int {
+		r := httptest.NewRequest(http.MethodGet, "/calltouch?"+query, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w.Code
+	}
+
+	if got := send("callId=1&callphase=callconnected"); got != http.StatusOK {
+		t.Fatalf("first status = %d, want %d", got, http.StatusOK)
+	}
+
+	// Единственный слот занят, уведомление отклоняется и не должно считаться полученным.
+	if got := send("callId=2&callphase=callconnected"); got != http.StatusServiceUnavailable {
+		t.Fatalf("busy status = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+
+	close(release)
+	h.Wait()
+
+	var dispatched int32
+
+	h.OnCall(func(_ context.Context, _ webhook.CallEvent) error {
+		atomic.AddInt32(&dispatched, 1)
+
+		return nil
+	})
+
+	if got := send("callId=2&callphase=callconnected"); got != http.StatusOK {
+		t.Fatalf("retry status = %d, want %d", got, http.StatusOK)
+	}
+
+	h.Wait()
+
+	if n := atomic.LoadInt32(&dispatched); n != 1 {
+		t.Errorf("retry dispatched %d times, want 1", n)
+	}
+}
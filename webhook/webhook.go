@@ -0,0 +1,325 @@
+// Package webhook принимает HTTP-уведомления Calltouch о звонках и заявках
+// и передает их зарегистрированным обработчикам в виде типизированных событий.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	calltouch "github.com/mg-realcom/calltouch-sdk"
+)
+
+const maxBodySize = 1 << 20
+
+var (
+	ErrUnknownEvent  = errors.New("webhook: payload is neither a call nor a lead notification")
+	ErrMissingCallID = errors.New("webhook: callId is required")
+	ErrMissingLeadID = errors.New("webhook: requestId is required")
+)
+
+type CallEvent struct {
+	SiteID int            // Идентификатор сайта из уведомления, 0 если не передан.
+	Call   calltouch.Call // Данные звонка из уведомления.
+	Raw    url.Values     // Исходные параметры уведомления.
+}
+
+type LeadEvent struct {
+	SiteID int            // Идентификатор сайта из уведомления, 0 если не передан.
+	Lead   calltouch.Lead // Данные заявки из уведомления.
+	Raw    url.Values     // Исходные параметры уведомления.
+}
+
+type CallHandlerFunc func(ctx context.Context, event CallEvent) error
+
+type LeadHandlerFunc func(ctx context.Context, event LeadEvent) error
+
+// Handler - http.Handler для уведомлений Calltouch. Уведомление проверяется (адрес отправителя, секрет,
+// повтор - см. AllowNetworks, RequireSecret, RejectReplays) и подтверждается сразу,
+// а обработчики вызываются в фоне, не более maxInFlight одновременно. Если все слоты заняты,
+// Calltouch получает 503 и повторит уведомление позже.
+type Handler struct {
+	mu           sync.RWMutex
+	callHandlers []CallHandlerFunc
+	leadHandlers []LeadHandlerFunc
+
+	allowed      []*net.IPNet
+	proxies      []*net.IPNet
+	secret       string
+	secretParam  string
+	secretHeader string
+	replays      *replayCache
+	enrichment   *Enrichment
+
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+
+	// Timeout ограничивает время работы обработчиков одного события. По умолчанию 30 секунд.
+	// Задается до начала обработки запросов.
+	Timeout time.Duration
+	// ErrorLog получает ошибки обработчиков. По умолчанию используется log.Println.
+	ErrorLog func(err error)
+}
+
+func NewHandler(maxInFlight int) *Handler {
+	if maxInFlight <= 0 {
+		maxInFlight = 1
+	}
+
+	return &Handler{
+		inFlight: make(chan struct{}, maxInFlight),
+		Timeout:  30 * time.Second,
+	}
+}
+
+// OnCall регистрирует обработчик уведомлений о звонках.
+func (h *Handler) OnCall(fn CallHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.callHandlers = append(h.callHandlers, fn)
+}
+
+// OnLead регистрирует обработчик уведомлений о заявках.
+func (h *Handler) OnLead(fn LeadHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.leadHandlers = append(h.leadHandlers, fn)
+}
+
+// Wait ожидает завершения всех запущенных обработчиков. Используется при остановке сервиса.
+func (h *Handler) Wait() {
+	h.wg.Wait()
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if !h.checkSource(r) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+		return
+	}
+
+	values, err := readValues(w, r)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if !h.checkSecret(r, values) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+		return
+	}
+
+	dispatch, err := h.decode(values)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	// Timeout читается под блокировкой: EnrichCalls может увеличить его на работающем обработчике.
+	h.mu.RLock()
+	replays, timeout := h.replays, h.Timeout
+	h.mu.RUnlock()
+
+	id := eventID(values)
+	if replays != nil && !replays.add(id) {
+		// Повтор подтверждается, чтобы Calltouch перестал его отправлять, но обработчикам не передается.
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	select {
+	case h.inFlight <- struct{}{}:
+	default:
+		if replays != nil {
+			replays.remove(id)
+		}
+
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+
+		return
+	}
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		defer func() { <-h.inFlight }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		dispatch(ctx)
+	}()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) decode(values url.Values) (func(ctx context.Context), error) {
+	siteID, _ := strconv.Atoi(values.Get("siteId"))
+
+	switch {
+	case values.Has("callId"):
+		call, err := decodeCall(values)
+		if err != nil {
+			return nil, err
+		}
+
+		event := CallEvent{SiteID: siteID, Call: call, Raw: values}
+
+		return func(ctx context.Context) { h.dispatchCall(ctx, event) }, nil
+	case values.Has("requestId"):
+		lead, err := decodeLead(values)
+		if err != nil {
+			return nil, err
+		}
+
+		event := LeadEvent{SiteID: siteID, Lead: lead, Raw: values}
+
+		return func(ctx context.Context) { h.dispatchLead(ctx, event) }, nil
+	default:
+		return nil, ErrUnknownEvent
+	}
+}
+
+func (h *Handler) dispatchCall(ctx context.Context, event CallEvent) {
+	h.mu.RLock()
+	handlers, enrichment := h.callHandlers, h.enrichment
+	h.mu.RUnlock()
+
+	if enrichment != nil {
+		var err error
+
+		event, err = enrichment.enrich(ctx, event)
+		if err != nil {
+			h.logError(err)
+		}
+	}
+
+	if ctx.Err() != nil {
+		h.logError(fmt.Errorf("webhook: call %d not dispatched: %w", event.Call.CallID, ctx.Err()))
+
+		return
+	}
+
+	for _, fn := range handlers {
+		err := fn(ctx, event)
+		if err != nil {
+			h.logError(err)
+		}
+	}
+}
+
+func (h *Handler) dispatchLead(ctx context.Context, event LeadEvent) {
+	h.mu.RLock()
+	handlers := h.leadHandlers
+	h.mu.RUnlock()
+
+	for _, fn := range handlers {
+		err := fn(ctx, event)
+		if err != nil {
+			h.logError(err)
+		}
+	}
+}
+
+func (h *Handler) logError(err error) {
+	if h.ErrorLog != nil {
+		h.ErrorLog(err)
+
+		return
+	}
+
+	log.Println(err)
+}
+
+// readValues собирает параметры уведомления из строки запроса и тела (form или JSON).
+func readValues(w http.ResponseWriter, r *http.Request) (url.Values, error) {
+	values := r.URL.Query()
+
+	if r.Method != http.MethodPost || r.Body == nil {
+		return values, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) == 0 {
+		return values, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var bodyValues url.Values
+
+	switch mediaType {
+	case "application/json":
+		bodyValues, err = jsonValues(body)
+	default:
+		bodyValues, err = url.ParseQuery(string(body))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range bodyValues {
+		values[k] = v
+	}
+
+	return values, nil
+}
+
+func jsonValues(body []byte) (url.Values, error) {
+	var raw map[string]json.RawMessage
+
+	err := json.Unmarshal(body, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	for k, v := range raw {
+		var s string
+		if json.Unmarshal(v, &s) == nil {
+			values.Set(k, s)
+
+			continue
+		}
+
+		if string(v) != "null" {
+			values.Set(k, string(v))
+		}
+	}
+
+	return values, nil
+}